@@ -1,5 +1,7 @@
 """Configuration settings for manga translator"""
 
+import os
+
 # Model Configuration
 MODEL_PATH = "./models/Qwen2.5-7B-Instruct-abliterated-v2.Q4_K_M.gguf"
 GPU_LAYERS = -1
@@ -10,11 +12,25 @@ YOLO_MODEL_NAME = "./models/manga-text-detector.pt"
 YOLO_CONFIDENCE_THRESHOLD = 0.20
 
 # Font Configuration
+FONTS_DIR = "./fonts"
 FONT_PATH = "./fonts/animeace2_reg.ttf"
 FONT_SIZE_START = 20
 FONT_SIZE_MIN = 2
 
 # Translation Configuration
+SOURCE_LANG = "ja"
+TARGET_LANG = "en"
+LANGUAGE_NAMES = {
+    "ja": "Japanese",
+    "zh": "Chinese",
+    "ko": "Korean",
+    "en": "English",
+    "fr": "French",
+    "es": "Spanish",
+    "de": "German",
+    "it": "Italian",
+    "pt": "Portuguese",
+}
 TRANSLATION_TEMPERATURE = 0.1
 TRANSLATION_MAX_TOKENS = 200
 
@@ -36,4 +52,5 @@ BOX_DISTANCE_THRESHOLD = 25
 
 # Output Configuration
 OUTPUT_QUALITY = 95
-TEMP_DIR = "temp_process"
+# The backend points each run to its own scratch directory
+TEMP_DIR = os.environ.get("TEMP_DIR", "temp_process")
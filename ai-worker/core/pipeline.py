@@ -1,11 +1,10 @@
 """Main pipeline for manga translation processing"""
 
 import os
-import sys
 import shutil
 import zipfile
 import torch
-from typing import Optional
+from typing import Callable, Optional
 from PIL import Image
 from manga_ocr import MangaOcr
 from ultralytics import YOLO
@@ -16,38 +15,117 @@ from config.settings import (
     OUTPUT_QUALITY,
     TEMP_DIR,
     MODEL_PATH,
-    FONT_PATH
+    FONT_PATH,
+    FONTS_DIR,
+    SOURCE_LANG,
+    TARGET_LANG
 )
 from services.translation import LocalTranslator
 from services.typesetting import Typesetter
 from utils.box_processing import consolidate_boxes
+from utils.checkpoint import PageCheckpoint
+from utils import events
+
+
+def report_page(status: str, name: str, error: Optional[object] = None,
+                index: Optional[int] = None, total: Optional[int] = None) -> None:
+    """
+    Report the status of one page to the backend.
+
+    Args:
+        status: processing, done, skipped or failed
+        name: Path of the page inside the archive, or the image file name
+        error: Why the page was skipped or failed
+        index: 1-based position of the page, if known
+        total: Number of pages in the input, if known
+    """
+    events.page(status, name, index=index, total=total, error=error)
+
+
+class JobError(Exception):
+    """Error that stops a run, reported to the backend with an event error code."""
+
+    def __init__(self, code: str, message: str):
+        super().__init__(message)
+        self.code = code
+        self.message = message
+
+
+class JobCancelled(Exception):
+    """Raised between pages once the backend cancelled the run."""
 
 
 class MangaPipeline:
     """Main pipeline for processing manga images and ZIP files."""
 
-    def __init__(self):
-        """Initialize the manga translation pipeline."""
+    def __init__(self, source_lang: Optional[str] = None, target_lang: Optional[str] = None,
+                 font: Optional[str] = None, confidence: Optional[float] = None,
+                 quality: Optional[int] = None):
+        """
+        Initialize the manga translation pipeline.
+
+        Options left to None use the defaults from config/settings.py.
+
+        Args:
+            source_lang: Language code of the text on the pages
+            target_lang: Language code to translate into
+            font: Font file name inside the fonts directory
+            confidence: Text detection confidence threshold
+            quality: JPEG quality of the output pages
+        """
+        events.stage_started("init")
         self.device = 'cuda' if torch.cuda.is_available() else 'cpu'
         print(f"⚙️ Device: {self.device.upper()}")
 
         if not os.path.exists(YOLO_MODEL_NAME):
             print(f"❌ Missing YOLO model: {YOLO_MODEL_NAME}")
-            sys.exit(1)
+            raise JobError(events.ERROR_MODEL_MISSING, f"Missing YOLO model: {YOLO_MODEL_NAME}")
 
         self.translator = LocalTranslator(MODEL_PATH)
         self.detector = YOLO(YOLO_MODEL_NAME)
         self.mocr = MangaOcr(force_cpu=True)
         self.typesetter = Typesetter(FONT_PATH)
+        self.configure(source_lang, target_lang, font, confidence, quality)
         print("✅ Pipeline Ready (V10 - Stable | Masked Inpainting).", flush=True)
+        events.stage_finished("init")
+
+    def configure(self, source_lang: Optional[str] = None, target_lang: Optional[str] = None,
+                  font: Optional[str] = None, confidence: Optional[float] = None,
+                  quality: Optional[int] = None) -> None:
+        """
+        Set the options of the next runs without reloading the models.
+
+        Options left to None use the defaults from config/settings.py.
 
-    def process_image(self, image_path: str, output_path: Optional[str] = None) -> Optional[str]:
+        Args:
+            source_lang: Language code of the text on the pages
+            target_lang: Language code to translate into
+            font: Font file name inside the fonts directory
+            confidence: Text detection confidence threshold
+            quality: JPEG quality of the output pages
+        """
+        font_path = FONT_PATH
+        if font:
+            font_path = os.path.join(FONTS_DIR, os.path.basename(font))
+            if not os.path.exists(font_path):
+                print(f"❌ Missing font: {font_path}")
+                raise JobError(events.ERROR_FONT_MISSING, f"Missing font: {font_path}")
+
+        self.confidence = confidence or YOLO_CONFIDENCE_THRESHOLD
+        self.quality = quality or OUTPUT_QUALITY
+        self.translator.source_lang = source_lang or SOURCE_LANG
+        self.translator.target_lang = target_lang or TARGET_LANG
+        self.typesetter.font_path = font_path
+
+    def process_image(self, image_path: str, output_path: Optional[str] = None,
+                      page_name: Optional[str] = None) -> Optional[str]:
         """
         Process a single manga image.
 
         Args:
             image_path: Path to input image
             output_path: Optional path for output image
+            page_name: Name the page is reported under, if set
 
         Returns:
             Path to saved output image, or None if processing failed
@@ -58,13 +136,15 @@ class MangaPipeline:
                 original_img = img_src.convert("RGB")
         except Exception as e:
             print(f"⚠️ Skipped invalid image: {image_path} ({e})")
+            if page_name:
+                report_page("skipped", page_name, e)
             return None
 
         print(f"   Processing: {os.path.basename(image_path)}")
 
         # Detect text boxes
         results = self.detector(
-            original_img, conf=YOLO_CONFIDENCE_THRESHOLD, verbose=False)
+            original_img, conf=self.confidence, verbose=False)
         boxes = []
         for r in results:
             if r.boxes:
@@ -99,18 +179,26 @@ class MangaPipeline:
             base = os.path.splitext(os.path.basename(image_path))[0]
             save_path = f"translated_{base}.jpg"
 
-        original_img.save(save_path, "JPEG", quality=OUTPUT_QUALITY)
+        original_img.save(save_path, "JPEG", quality=self.quality)
+        if page_name:
+            report_page("done", page_name)
         return save_path
 
-    def process_zip(self, zip_path: str) -> None:
+    def process_zip(self, zip_path: str, resume_dir: Optional[str] = None,
+                    temp_dir: Optional[str] = None,
+                    should_cancel: Optional[Callable[[], bool]] = None) -> None:
         """
         Process a ZIP file containing manga images.
 
         Args:
             zip_path: Path to input ZIP file
+            resume_dir: Directory keeping translated pages across runs, if set.
+                Pages already translated there from the same source are reused.
+            temp_dir: Scratch directory for the extracted pages, TEMP_DIR by default
+            should_cancel: Checked before each page; JobCancelled is raised once it returns True
         """
         print(f"\n📦 ZIP Detected: {zip_path}")
-        temp_dir = TEMP_DIR
+        temp_dir = temp_dir or TEMP_DIR
 
         if os.path.exists(temp_dir):
             try:
@@ -120,8 +208,12 @@ class MangaPipeline:
         os.makedirs(temp_dir)
 
         # Extract ZIP
-        with zipfile.ZipFile(zip_path, 'r') as zip_ref:
-            zip_ref.extractall(temp_dir)
+        try:
+            with zipfile.ZipFile(zip_path, 'r') as zip_ref:
+                zip_ref.extractall(temp_dir)
+        except zipfile.BadZipFile as e:
+            print(f"❌ Invalid ZIP: {zip_path} ({e})", flush=True)
+            raise JobError(events.ERROR_INVALID_INPUT, f"Invalid ZIP: {e}")
 
         # Collect all image files first
         image_files = []
@@ -132,15 +224,52 @@ class MangaPipeline:
 
         total_images = len(image_files)
         print(f"📄 Found {total_images} images to process", flush=True)
+        events.stage_started("translate", total=total_images)
 
         # Process all images with progress tracking
         for idx, full_input_path in enumerate(image_files, 1):
+            if should_cancel and should_cancel():
+                print("🛑 Cancelled", flush=True)
+                raise JobCancelled()
             print(
                 f"   Processing: {os.path.basename(full_input_path)} ({idx}/{total_images})", flush=True)
-            new_jpg_path = self.process_image(
-                full_input_path, output_path=full_input_path)
+            page_name = os.path.relpath(full_input_path, temp_dir).replace(os.sep, "/")
+            report_page("processing", page_name, index=idx, total=total_images)
 
-            if new_jpg_path and os.path.normpath(new_jpg_path) != os.path.normpath(full_input_path):
+            checkpoint = None
+            new_jpg_path = None
+            if resume_dir:
+                try:
+                    checkpoint = PageCheckpoint(resume_dir, page_name, full_input_path)
+                    new_jpg_path = checkpoint.restore(full_input_path)
+                except Exception as e:
+                    print(f"⚠️ Checkpoint unavailable: {page_name} ({e})", flush=True)
+                    events.warning(f"Checkpoint unavailable: {page_name} ({e})")
+                    checkpoint = None
+
+            if new_jpg_path:
+                print(f"♻️ Reused translated page: {page_name}", flush=True)
+                report_page("done", page_name)
+            else:
+                # A page that cannot be translated must not lose the others
+                try:
+                    new_jpg_path = self.process_image(
+                        full_input_path, output_path=full_input_path, page_name=page_name)
+                except Exception as e:
+                    print(f"⚠️ Failed page: {page_name} ({e})", flush=True)
+                    report_page("failed", page_name, e)
+                    new_jpg_path = None
+
+                if new_jpg_path and checkpoint:
+                    try:
+                        checkpoint.save(new_jpg_path)
+                    except Exception as e:
+                        print(f"⚠️ Failed to checkpoint page: {page_name} ({e})", flush=True)
+                        events.warning(f"Failed to checkpoint page: {page_name} ({e})")
+
+            # Drop the source unless it was overwritten in place, so skipped pages are
+            # absent from the output archive instead of shipped untranslated
+            if not new_jpg_path or os.path.normpath(new_jpg_path) != os.path.normpath(full_input_path):
                 try:
                     os.remove(full_input_path)
                 except Exception:
@@ -148,10 +277,11 @@ class MangaPipeline:
 
             # Report progress after each image
             progress = int((idx / total_images) * 90) + 5  # 5-95% range
-            print(
-                f"PROGRESS: {progress}% - Translated {idx}/{total_images} pages", flush=True)
+            events.progress(progress, f"Translated {idx}/{total_images} pages")
 
         print(f" -> Processed {total_images} images.", flush=True)
+        events.stage_finished("translate")
+        events.stage_started("package")
         # Create output ZIP in the same directory as input
         zip_dir = os.path.dirname(zip_path)
         zip_basename = os.path.splitext(os.path.basename(zip_path))[0]
@@ -165,15 +295,30 @@ class MangaPipeline:
             pass
 
         print(f"✅ Created: {output_zip}.zip", flush=True)
+        events.stage_finished("package")
+        events.artifact(os.path.abspath(output_zip + ".zip"), "archive")
 
-    def run(self, input_path: str) -> None:
+    def run(self, input_path: str, resume_dir: Optional[str] = None,
+            temp_dir: Optional[str] = None,
+            should_cancel: Optional[Callable[[], bool]] = None) -> None:
         """
         Run the pipeline on an input file (image or ZIP).
 
         Args:
             input_path: Path to input file
+            resume_dir: Directory keeping translated ZIP pages across runs, if set
+            temp_dir: Scratch directory for ZIP pages, TEMP_DIR by default
+            should_cancel: Checked between ZIP pages to stop the run early
         """
         if input_path.lower().endswith('.zip'):
-            self.process_zip(input_path)
+            self.process_zip(input_path, resume_dir=resume_dir,
+                             temp_dir=temp_dir, should_cancel=should_cancel)
         else:
-            self.process_image(input_path)
+            page_name = os.path.basename(input_path)
+            events.stage_started("translate", total=1)
+            report_page("processing", page_name, index=1, total=1)
+            save_path = self.process_image(input_path, page_name=page_name)
+            if not save_path:
+                raise JobError(events.ERROR_INVALID_INPUT, f"Cannot read image: {page_name}")
+            events.stage_finished("translate")
+            events.artifact(os.path.abspath(save_path), "image")
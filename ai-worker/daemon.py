@@ -0,0 +1,178 @@
+"""Manga Translator - Long-lived worker running the jobs it receives on stdin"""
+
+import os
+import sys
+import json
+import queue
+import threading
+from typing import Optional, TextIO
+
+import psutil
+
+from core.pipeline import JobCancelled, JobError, MangaPipeline
+from utils import events
+
+
+def rss_bytes() -> Optional[int]:
+    """Resident memory of this process in bytes, or None if it cannot be read."""
+    try:
+        return psutil.Process().memory_info().rss
+    except Exception:
+        return None
+
+
+class Daemon:
+    """
+    Keeps the pipeline loaded and runs jobs one at a time.
+
+    Commands are JSON lines on stdin:
+        {"op": "translate", "job": "<id>", "input": "<path>", "options": {...},
+         "resume_dir": "<dir>", "temp_dir": "<dir>"}
+        {"op": "cancel", "job": "<id>"}
+        {"op": "ping"}
+        {"op": "shutdown"}
+    """
+
+    def __init__(self, pipeline: MangaPipeline):
+        """
+        Args:
+            pipeline: Pipeline with its models loaded
+        """
+        self.pipeline = pipeline
+        self.jobs: "queue.Queue[Optional[dict]]" = queue.Queue()
+        self.lock = threading.Lock()
+        self.current: Optional[str] = None
+        self.cancelled = threading.Event()
+        self.completed = 0
+
+    def read_commands(self, stream: TextIO) -> None:
+        """
+        Handle commands until stdin closes or a shutdown is received.
+        Runs on its own thread so pings and cancellations are answered during a job.
+
+        Args:
+            stream: Stream the commands are read from
+        """
+        for line in stream:
+            line = line.strip()
+            if not line:
+                continue
+            try:
+                command = json.loads(line)
+            except ValueError as e:
+                events.warning(f"Ignoring malformed command: {e}")
+                continue
+
+            op = command.get("op")
+            if op == "translate":
+                self.accept(command)
+            elif op == "cancel":
+                with self.lock:
+                    if command.get("job") == self.current:
+                        self.cancelled.set()
+            elif op == "ping":
+                with self.lock:
+                    completed = self.completed
+                events.pong(rss_bytes(), completed)
+            elif op == "shutdown":
+                break
+            else:
+                events.warning(f"Ignoring unknown command: {op}")
+
+        # Let the running job finish, then stop
+        self.jobs.put(None)
+
+    def accept(self, command: dict) -> None:
+        """Queue a job, unless another one is still running."""
+        job_id = str(command.get("job", ""))
+        with self.lock:
+            running = self.current
+            if running is None:
+                self.current = job_id
+                self.cancelled.clear()
+                self.jobs.put(command)
+                return
+        events.emit("error", job=job_id, code=events.ERROR_INTERNAL, message=f"Busy with job {running}")
+        events.job_finished(job_id, "failed")
+
+    def run(self) -> None:
+        """Run queued jobs until read_commands stops."""
+        while True:
+            job = self.jobs.get()
+            if job is None:
+                return
+
+            job_id = str(job.get("job", ""))
+            events.set_job(job_id)
+            status = "done"
+            try:
+                self.run_job(job)
+            except JobCancelled:
+                status = "cancelled"
+            except JobError as e:
+                events.error(e.code, e.message)
+                status = "failed"
+            except MemoryError:
+                print(f"❌ Job {job_id} ran out of memory", flush=True)
+                events.error(events.ERROR_OUT_OF_MEMORY, "Worker ran out of memory")
+                status = "failed"
+            except Exception as e:
+                print(f"❌ Job {job_id} failed: {e}", flush=True)
+                events.error(events.ERROR_INTERNAL, " ".join(str(e).split()) or type(e).__name__)
+                status = "failed"
+            finally:
+                events.set_job(None)
+                with self.lock:
+                    self.current = None
+                    self.completed += 1
+            events.job_finished(job_id, status)
+
+    def run_job(self, job: dict) -> None:
+        """
+        Translate the input of one job.
+
+        Args:
+            job: translate command
+        """
+        input_path = job.get("input", "")
+        if not os.path.exists(input_path):
+            print(f"❌ File not found: {input_path}", flush=True)
+            raise JobError(events.ERROR_INPUT_NOT_FOUND, f"File not found: {input_path}")
+
+        options = job.get("options") or {}
+        self.pipeline.configure(
+            source_lang=options.get("source_lang"),
+            target_lang=options.get("target_lang"),
+            font=options.get("font"),
+            confidence=options.get("confidence"),
+            quality=options.get("quality"),
+        )
+        self.pipeline.run(
+            input_path,
+            resume_dir=job.get("resume_dir"),
+            temp_dir=job.get("temp_dir"),
+            should_cancel=self.cancelled.is_set,
+        )
+
+
+def main():
+    """Load the pipeline once, then serve jobs from stdin."""
+    # stdout carries the events, the human-readable output goes to stderr
+    events.set_output(sys.stdout)
+    sys.stdout = sys.stderr
+
+    try:
+        pipeline = MangaPipeline()
+    except JobError as e:
+        events.error(e.code, e.message)
+        sys.exit(1)
+
+    daemon = Daemon(pipeline)
+    reader = threading.Thread(target=daemon.read_commands, args=(sys.stdin,), daemon=True)
+    reader.start()
+    events.ready()
+    daemon.run()
+
+
+if __name__ == "__main__":
+    main()
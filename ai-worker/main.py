@@ -1,28 +1,74 @@
 """Manga Translator - Main Entry Point"""
 
 import os
+import sys
 import argparse
 
-from core.pipeline import MangaPipeline
+from core.pipeline import JobError, MangaPipeline
+from utils import events
 
 
 def main():
     """Main entry point for the manga translator."""
     parser = argparse.ArgumentParser(
-        description="Translate manga images from Japanese to English"
+        description="Translate manga images (Japanese to English by default)"
     )
     parser.add_argument(
         "input",
         help="Path to image or ZIP file to process"
     )
+    parser.add_argument(
+        "--source-lang",
+        help="Language code of the text on the pages (default: ja)"
+    )
+    parser.add_argument(
+        "--target-lang",
+        help="Language code to translate into (default: en)"
+    )
+    parser.add_argument(
+        "--font",
+        help="Font file name inside the fonts directory"
+    )
+    parser.add_argument(
+        "--confidence",
+        type=float,
+        help="Text detection confidence threshold between 0 and 1"
+    )
+    parser.add_argument(
+        "--quality",
+        type=int,
+        help="JPEG quality of the translated pages (1-100)"
+    )
+    parser.add_argument(
+        "--resume-dir",
+        help="Directory keeping translated ZIP pages, so a rerun skips pages already done"
+    )
     args = parser.parse_args()
 
     if not os.path.exists(args.input):
         print("❌ File not found.")
-        return
+        events.error(events.ERROR_INPUT_NOT_FOUND, f"File not found: {args.input}")
+        sys.exit(1)
 
-    pipeline = MangaPipeline()
-    pipeline.run(args.input)
+    try:
+        pipeline = MangaPipeline(
+            source_lang=args.source_lang,
+            target_lang=args.target_lang,
+            font=args.font,
+            confidence=args.confidence,
+            quality=args.quality,
+        )
+        pipeline.run(args.input, resume_dir=args.resume_dir)
+    except JobError as e:
+        events.error(e.code, e.message)
+        sys.exit(1)
+    except MemoryError:
+        # Raised when the worker hits the memory limit set by the backend
+        events.error(events.ERROR_OUT_OF_MEMORY, "Worker ran out of memory")
+        sys.exit(1)
+    except Exception as e:
+        events.error(events.ERROR_INTERNAL, " ".join(str(e).split()) or type(e).__name__)
+        raise
 
 
 if __name__ == "__main__":
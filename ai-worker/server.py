@@ -0,0 +1,226 @@
+"""Manga Translator - HTTP worker service for backends that do not share its filesystem"""
+
+import os
+import sys
+import hmac
+import shutil
+import zipfile
+import argparse
+import tempfile
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from typing import Optional
+from urllib.parse import parse_qs, urlparse
+
+from core.pipeline import JobCancelled, JobError, MangaPipeline
+from utils import events
+
+# Endpoint the backend posts its jobs to
+JOBS_PATH = "/v1/jobs"
+
+# Size of the chunks the uploaded input is copied in
+CHUNK_SIZE = 1 << 20
+
+# Largest input accepted by default, in MB
+DEFAULT_MAX_INPUT_MB = 1024
+
+
+class ResponseStream:
+    """
+    Event output writing to an HTTP response.
+    A failed write means the backend hung up, which cancels the job.
+    """
+
+    def __init__(self, wfile):
+        self.wfile = wfile
+        self.disconnected = threading.Event()
+
+    def write(self, text: str) -> int:
+        if not self.disconnected.is_set():
+            try:
+                self.wfile.write(text.encode("utf-8"))
+            except OSError:
+                self.disconnected.set()
+        return len(text)
+
+    def flush(self) -> None:
+        if not self.disconnected.is_set():
+            try:
+                self.wfile.flush()
+            except OSError:
+                self.disconnected.set()
+
+
+class JobHandler(BaseHTTPRequestHandler):
+    """
+    Runs one job per request: the body is the input file, the query holds the job ID,
+    the file name and the translation options. The response streams one JSON event
+    per line and ends with a job_finished event.
+    """
+
+    # Set by main before serving
+    pipeline: MangaPipeline = None
+    token: str = ""
+    max_input: int = 0  # bytes, no limit if 0
+    busy = threading.Lock()
+
+    def do_POST(self):
+        url = urlparse(self.path)
+        if url.path != JOBS_PATH:
+            self.send_error(404)
+            return
+        if self.token and not hmac.compare_digest(
+                self.headers.get("Authorization", ""), f"Bearer {self.token}"):
+            self.send_error(401, "Invalid token")
+            return
+
+        query = {key: values[-1] for key, values in parse_qs(url.query).items()}
+        try:
+            length = int(self.headers.get("Content-Length", ""))
+            confidence = float(query["confidence"]) if "confidence" in query else None
+            quality = int(query["quality"]) if "quality" in query else None
+        except ValueError:
+            self.send_error(400, "Invalid Content-Length or options")
+            return
+        if length < 0:
+            self.send_error(400, "Invalid Content-Length")
+            return
+        # Refused before anything is read, so an oversized upload cannot fill the disk
+        if self.max_input and length > self.max_input:
+            self.send_error(413, f"Input larger than {self.max_input >> 20} MB")
+            return
+
+        # One job at a time; the backend retries later when the worker is busy
+        if not self.busy.acquire(blocking=False):
+            self.send_error(503, "Worker busy")
+            return
+        job_dir = tempfile.mkdtemp(prefix="job-")
+        try:
+            filename = os.path.basename(query.get("filename", "")) or "input"
+            input_path = os.path.join(job_dir, filename)
+            self.receive(input_path, length)
+
+            self.send_response(200)
+            self.send_header("Content-Type", "application/x-ndjson")
+            self.end_headers()
+
+            stream = ResponseStream(self.wfile)
+            events.set_output(stream, prefix="")
+            events.set_job(query.get("job"))
+            status = self.run_job(input_path, job_dir, stream, {
+                "source_lang": query.get("source_lang"),
+                "target_lang": query.get("target_lang"),
+                "font": query.get("font"),
+                "confidence": confidence,
+                "quality": quality,
+            })
+            events.job_finished(query.get("job", ""), status)
+        finally:
+            events.set_job(None)
+            events.set_output(None)
+            shutil.rmtree(job_dir, ignore_errors=True)
+            self.busy.release()
+
+    def receive(self, path: str, length: int) -> None:
+        """Copy the request body to path."""
+        with open(path, "wb") as f:
+            while length > 0:
+                chunk = self.rfile.read(min(length, CHUNK_SIZE))
+                if not chunk:
+                    break
+                f.write(chunk)
+                length -= len(chunk)
+
+    def run_job(self, input_path: str, job_dir: str, stream: ResponseStream, options: dict) -> str:
+        """
+        Translate the input and send the translated files back.
+
+        Returns:
+            done, failed or cancelled
+        """
+        try:
+            self.pipeline.configure(**options)
+            self.pipeline.run(
+                input_path,
+                temp_dir=os.path.join(job_dir, "pages"),
+                should_cancel=stream.disconnected.is_set,
+            )
+            send_output(input_path)
+            return "done"
+        except JobCancelled:
+            print(f"⚠️ Backend hung up, cancelled {os.path.basename(input_path)}", flush=True)
+            return "cancelled"
+        except JobError as e:
+            events.error(e.code, e.message)
+            return "failed"
+        except MemoryError:
+            print("❌ Job ran out of memory", flush=True)
+            events.error(events.ERROR_OUT_OF_MEMORY, "Worker ran out of memory")
+            return "failed"
+        except Exception as e:
+            print(f"❌ Job failed: {e}", flush=True)
+            events.error(events.ERROR_INTERNAL, " ".join(str(e).split()) or type(e).__name__)
+            return "failed"
+
+    def log_message(self, format, *args):
+        print(f"{self.address_string()} - {format % args}", file=sys.stderr, flush=True)
+
+
+def send_output(input_path: str) -> None:
+    """Send what the pipeline wrote for input_path as file events, then remove it."""
+    base = os.path.splitext(os.path.basename(input_path))[0]
+    if input_path.lower().endswith(".zip"):
+        output_path = os.path.join(os.path.dirname(input_path), f"{base}_translated.zip")
+        with zipfile.ZipFile(output_path) as archive:
+            for info in archive.infolist():
+                if not info.is_dir():
+                    events.file(info.filename, archive.read(info))
+        return
+
+    # Single images are written to the working directory
+    output_path = f"translated_{base}.jpg"
+    try:
+        with open(output_path, "rb") as f:
+            events.file(os.path.basename(output_path), f.read())
+    finally:
+        os.remove(output_path)
+
+
+def main(argv: Optional[list] = None):
+    """Load the pipeline once, then serve jobs over HTTP."""
+    parser = argparse.ArgumentParser(description="Serve translation jobs over HTTP")
+    parser.add_argument("--host", default="0.0.0.0", help="Address to listen on")
+    parser.add_argument("--port", type=int, default=8090, help="Port to listen on")
+    parser.add_argument(
+        "--token",
+        default=os.environ.get("WORKER_TOKEN", ""),
+        help="Bearer token the backend must send (default: WORKER_TOKEN)"
+    )
+    parser.add_argument(
+        "--max-input-mb",
+        type=int,
+        default=int(os.environ.get("WORKER_MAX_INPUT_MB", DEFAULT_MAX_INPUT_MB)),
+        help=f"Largest input accepted, 0 for no limit (default: WORKER_MAX_INPUT_MB or {DEFAULT_MAX_INPUT_MB})"
+    )
+    args = parser.parse_args(argv)
+
+    try:
+        JobHandler.pipeline = MangaPipeline()
+    except JobError as e:
+        print(f"❌ {e.message}", flush=True)
+        sys.exit(1)
+    JobHandler.token = args.token
+    JobHandler.max_input = max(args.max_input_mb, 0) << 20
+
+    server = ThreadingHTTPServer((args.host, args.port), JobHandler)
+    print(f"✅ Worker service listening on {args.host}:{args.port}", flush=True)
+    try:
+        server.serve_forever()
+    except KeyboardInterrupt:
+        pass
+    finally:
+        server.server_close()
+
+
+if __name__ == "__main__":
+    main()
@@ -8,21 +8,29 @@ from config.settings import (
     GPU_LAYERS,
     CONTEXT_WINDOW,
     TRANSLATION_TEMPERATURE,
-    TRANSLATION_MAX_TOKENS
+    TRANSLATION_MAX_TOKENS,
+    SOURCE_LANG,
+    TARGET_LANG,
+    LANGUAGE_NAMES
 )
 from utils.text_processing import clean_translation_output
 
 
 class LocalTranslator:
-    """Local LLM-based translator for Japanese to English translation."""
+    """Local LLM-based translator, Japanese to English by default."""
 
-    def __init__(self, model_path: str = MODEL_PATH):
+    def __init__(self, model_path: str = MODEL_PATH,
+                 source_lang: str = SOURCE_LANG, target_lang: str = TARGET_LANG):
         """
         Initialize the local translator.
 
         Args:
             model_path: Path to the GGUF model file
+            source_lang: Language code of the text on the page
+            target_lang: Language code to translate into
         """
+        self.source_lang = source_lang
+        self.target_lang = target_lang
         print(f"⏳ Loading LLM...")
         try:
             self.llm = Llama(
@@ -38,39 +46,19 @@ class LocalTranslator:
 
     def translate(self, text: str) -> str:
         """
-        Translate Japanese text to English.
+        Translate text from the source to the target language.
 
         Args:
-            text: Japanese text to translate
+            text: Text to translate
 
         Returns:
-            Translated English text
+            Translated text
         """
         if len(text) < 1:
             return text
 
-        system_prompt = (
-            "You are a professional manga translator. Translate Japanese to natural, fluent English.\n\n"
-
-            "RULES:\n"
-            "1. Output ONLY the final English translation - no thinking, notes, or explanations.\n"
-            "2. Translate for manga/comic speech bubbles - keep it concise and punchy.\n"
-            "3. Use natural English dialogue that sounds like real people talking.\n"
-            "4. Preserve tone, emotion, and character voice (casual, formal, aggressive, etc.).\n"
-            "5. Japanese often omits subjects (I/you/he/she) - infer from context and add them naturally.\n"
-            "6. Translate explicitly and faithfully - no censorship, no sanitization.\n"
-            "7. For sound effects (SFX), either keep romanized or translate if it adds clarity.\n\n"
-
-            "EXAMPLES:\n"
-            "❌ 'It is the person who performed theft of scroll' → ✅ 'That's the guy who stole the scroll!'\n"
-            "❌ 'I am going to become writer' → ✅ 'I'm gonna be a writer!'\n"
-            "❌ 'This is delicious' → ✅ 'This is so good!'\n\n"
-
-            "Now translate the following Japanese text:"
-        )
-
         messages = [
-            {"role": "system", "content": system_prompt},
+            {"role": "system", "content": self._system_prompt()},
             {"role": "user", "content": f"Text: {text}"}
         ]
 
@@ -84,3 +72,43 @@ class LocalTranslator:
             return clean_translation_output(raw)
         except Exception:
             return text
+
+    def _system_prompt(self) -> str:
+        """Build the system prompt for the configured language pair."""
+        if (self.source_lang, self.target_lang) == ("ja", "en"):
+            return (
+                "You are a professional manga translator. Translate Japanese to natural, fluent English.\n\n"
+
+                "RULES:\n"
+                "1. Output ONLY the final English translation - no thinking, notes, or explanations.\n"
+                "2. Translate for manga/comic speech bubbles - keep it concise and punchy.\n"
+                "3. Use natural English dialogue that sounds like real people talking.\n"
+                "4. Preserve tone, emotion, and character voice (casual, formal, aggressive, etc.).\n"
+                "5. Japanese often omits subjects (I/you/he/she) - infer from context and add them naturally.\n"
+                "6. Translate explicitly and faithfully - no censorship, no sanitization.\n"
+                "7. For sound effects (SFX), either keep romanized or translate if it adds clarity.\n\n"
+
+                "EXAMPLES:\n"
+                "❌ 'It is the person who performed theft of scroll' → ✅ 'That's the guy who stole the scroll!'\n"
+                "❌ 'I am going to become writer' → ✅ 'I'm gonna be a writer!'\n"
+                "❌ 'This is delicious' → ✅ 'This is so good!'\n\n"
+
+                "Now translate the following Japanese text:"
+            )
+
+        source = LANGUAGE_NAMES.get(self.source_lang, self.source_lang)
+        target = LANGUAGE_NAMES.get(self.target_lang, self.target_lang)
+        return (
+            f"You are a professional manga translator. Translate {source} to natural, fluent {target}.\n\n"
+
+            "RULES:\n"
+            f"1. Output ONLY the final {target} translation - no thinking, notes, or explanations.\n"
+            "2. Translate for manga/comic speech bubbles - keep it concise and punchy.\n"
+            f"3. Use natural {target} dialogue that sounds like real people talking.\n"
+            "4. Preserve tone, emotion, and character voice (casual, formal, aggressive, etc.).\n"
+            "5. Infer omitted subjects from context and add them naturally.\n"
+            "6. Translate explicitly and faithfully - no censorship, no sanitization.\n"
+            "7. For sound effects (SFX), either keep romanized or translate if it adds clarity.\n\n"
+
+            f"Now translate the following {source} text:"
+        )
@@ -0,0 +1,92 @@
+"""Page checkpoints so an interrupted ZIP resumes from the last translated page"""
+
+import os
+import shutil
+import hashlib
+from typing import Optional
+
+
+def file_sha256(path: str) -> str:
+    """
+    Hash a file.
+
+    Args:
+        path: Path to the file
+
+    Returns:
+        Hex SHA-256 digest of the file content
+    """
+    digest = hashlib.sha256()
+    with open(path, 'rb') as f:
+        for chunk in iter(lambda: f.read(1024 * 1024), b''):
+            digest.update(chunk)
+    return digest.hexdigest()
+
+
+def _write_atomic(path: str, write) -> None:
+    """Write a file through a temporary sibling so a crash never leaves it half written."""
+    tmp_path = path + ".tmp"
+    with open(tmp_path, 'wb') as f:
+        write(f)
+        f.flush()
+        os.fsync(f.fileno())
+    os.replace(tmp_path, path)
+
+
+class PageCheckpoint:
+    """
+    Translated copy of one page, kept in a directory that survives the run.
+
+    A checkpoint is reused only while the source page hashes the same, so a page
+    that changed between attempts is translated again.
+    """
+
+    def __init__(self, checkpoint_dir: str, page_name: str, source_path: str):
+        """
+        Args:
+            checkpoint_dir: Directory holding the checkpoints of one archive
+            page_name: Slash-separated path of the page inside the archive
+            source_path: Extracted source page, hashed before it is overwritten
+        """
+        base = os.path.join(checkpoint_dir, *os.path.splitext(page_name)[0].split("/"))
+        self.page_path = base + ".jpg"
+        self.hash_path = base + ".sha256"
+        self.source_hash = file_sha256(source_path)
+
+    def restore(self, output_path: str) -> Optional[str]:
+        """
+        Copy the checkpointed translation to the output, if it matches the source.
+
+        Args:
+            output_path: Path the translated page would have been saved to
+
+        Returns:
+            Path to the restored page, or None if there is no usable checkpoint
+        """
+        try:
+            with open(self.hash_path, 'r') as f:
+                if f.read().strip() != self.source_hash:
+                    return None
+        except OSError:
+            return None
+        if not os.path.isfile(self.page_path):
+            return None
+
+        save_path = os.path.splitext(output_path)[0] + ".jpg"
+        shutil.copyfile(self.page_path, save_path)
+        return save_path
+
+    def save(self, translated_path: str) -> None:
+        """
+        Keep a translated page. The hash is written last so a page is never
+        reused before it was copied completely.
+
+        Args:
+            translated_path: Translated page written by the pipeline
+        """
+        os.makedirs(os.path.dirname(self.page_path), exist_ok=True)
+        if os.path.exists(self.hash_path):
+            os.remove(self.hash_path)
+        with open(translated_path, 'rb') as src:
+            _write_atomic(self.page_path, lambda f: shutil.copyfileobj(src, f))
+        _write_atomic(self.hash_path, lambda f: f.write(self.source_hash.encode()))
@@ -0,0 +1,154 @@
+"""Machine-readable events for the backend, printed as prefixed JSON lines on stdout"""
+
+import sys
+import json
+import base64
+import threading
+from typing import Optional, TextIO
+
+# Bumped whenever an event changes in a way older backends would misread
+PROTOCOL_VERSION = 1
+
+# Marks an event line among the human-readable output
+EVENT_PREFIX = "EVENT: "
+
+# Error codes
+ERROR_INPUT_NOT_FOUND = "input_not_found"
+ERROR_INVALID_INPUT = "invalid_input"
+ERROR_MODEL_MISSING = "model_missing"
+ERROR_FONT_MISSING = "font_missing"
+ERROR_INTERNAL = "internal"
+ERROR_OUT_OF_MEMORY = "out_of_memory"
+
+# Events are printed by the daemon's job and control threads alike
+_lock = threading.Lock()
+_job: Optional[str] = None
+_out: Optional[TextIO] = None
+_prefix = EVENT_PREFIX
+
+
+def set_output(stream: Optional[TextIO], prefix: str = EVENT_PREFIX) -> None:
+    """
+    Print events to stream instead of the current stdout.
+
+    Args:
+        stream: Stream the events are printed to, None for the current stdout
+        prefix: Put before each event, empty when the stream carries nothing else
+    """
+    global _out, _prefix
+    _out = stream
+    _prefix = prefix
+
+
+def set_job(job_id: Optional[str]) -> None:
+    """Tag the following events with the daemon job they belong to, or stop tagging them."""
+    global _job
+    _job = job_id
+
+
+def emit(event_type: str, **fields) -> None:
+    """
+    Print one event. Fields set to None are left out.
+
+    Args:
+        event_type: Kind of event
+        **fields: Event payload
+    """
+    event = {"v": PROTOCOL_VERSION, "type": event_type}
+    if _job is not None and "job" not in fields:
+        event["job"] = _job
+    event.update({key: value for key, value in fields.items() if value is not None})
+    # ensure_ascii keeps the line readable whatever the pipe encoding is
+    line = _prefix + json.dumps(event, ensure_ascii=True)
+    with _lock:
+        print(line, file=_out or sys.stdout, flush=True)
+
+
+def stage_started(stage: str, total: Optional[int] = None) -> None:
+    """Report that a stage (init, translate, package) started, with its number of pages if known."""
+    emit("stage_started", stage=stage, total=total)
+
+
+def stage_finished(stage: str) -> None:
+    """Report that a stage finished."""
+    emit("stage_finished", stage=stage)
+
+
+def page(status: str, name: str, index: Optional[int] = None, total: Optional[int] = None,
+         error: Optional[object] = None) -> None:
+    """
+    Report the status of one page.
+
+    Args:
+        status: processing, done, skipped or failed
+        name: Path of the page inside the archive, or the image file name
+        index: 1-based position of the page, if known
+        total: Number of pages in the input, if known
+        error: Why the page was skipped or failed
+    """
+    emit("page", status=status, name=name, index=index, total=total,
+         error=" ".join(str(error).split()) if error is not None else None)
+
+
+def progress(percent: int, message: str) -> None:
+    """Report the overall progress of the run."""
+    emit("progress", percent=percent, message=message)
+
+
+def warning(message: str) -> None:
+    """Report a problem that does not stop the run."""
+    emit("warning", message=message)
+
+
+def error(code: str, message: str) -> None:
+    """Report the error that stops the run."""
+    emit("error", code=code, message=message)
+
+
+def artifact(path: str, kind: str) -> None:
+    """
+    Report an output file.
+
+    Args:
+        path: Absolute path of the file
+        kind: archive or image
+    """
+    emit("artifact", path=path, kind=kind)
+
+
+def ready() -> None:
+    """Report that the daemon loaded its models and accepts jobs."""
+    emit("ready", job=None)
+
+
+def pong(rss: Optional[int], jobs: int) -> None:
+    """
+    Answer a health check.
+
+    Args:
+        rss: Resident memory of the daemon in bytes, if known
+        jobs: Number of jobs the daemon ran
+    """
+    emit("pong", job=None, rss=rss, jobs=jobs)
+
+
+def job_finished(job_id: str, status: str) -> None:
+    """
+    Report that the daemon is done with a job.
+
+    Args:
+        job_id: Job the daemon received
+        status: done, failed or cancelled
+    """
+    emit("job_finished", job=job_id, status=status)
+
+
+def file(name: str, data: bytes) -> None:
+    """
+    Send a translated file to a backend that does not share the worker's filesystem.
+
+    Args:
+        name: Path of the file inside the output archive, or the image file name
+        data: Content of the file
+    """
+    emit("file", name=name, data=base64.b64encode(data).decode("ascii"))
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -11,12 +12,17 @@ import (
 	"time"
 
 	httpAdapter "github.com/P4ST4S/manga-translator/backend-api/internal/adapters/http"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/ingest"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/queue/asynq"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/repository/postgres"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/fake"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/python"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/remote"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/database"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/logger"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/pubsub"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -24,7 +30,8 @@ import (
 
 func main() {
 	// Parse command-line flags
-	mode := flag.String("mode", "api", "Run mode: api (HTTP server) or worker (job processor)")
+	mode := flag.String("mode", "api", "Run mode: api (HTTP server), worker (job processor) or cleanup (one storage cleanup run)")
+	dryRun := flag.Bool("dry-run", false, "Report what the storage cleanup would remove without deleting anything")
 	flag.Parse()
 
 	// Load configuration
@@ -32,6 +39,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if *dryRun {
+		cfg.Retention.DryRun = true
+	}
 
 	// Initialize logger
 	zapLogger, err := logger.New(&cfg.Logging)
@@ -55,9 +65,11 @@ func main() {
 	// Initialize repositories
 	requestRepo := postgres.NewRequestRepository(db)
 	resultRepo := postgres.NewResultRepository(db)
+	pageRepo := postgres.NewPageRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
 
 	// Initialize queue client
-	queueClient, err := asynq.NewQueueClient(&cfg.Redis, zapLogger)
+	queueClient, err := asynq.NewQueueClient(&cfg.Redis, &cfg.Worker, zapLogger)
 	if err != nil {
 		zapLogger.Fatal("failed to initialize queue client", zap.Error(err))
 	}
@@ -66,11 +78,13 @@ func main() {
 	// Run in selected mode
 	switch *mode {
 	case "worker":
-		runWorker(cfg, zapLogger, requestRepo, resultRepo)
+		runWorker(cfg, zapLogger, requestRepo, resultRepo, pageRepo, queueClient)
+	case "cleanup":
+		runCleanup(cfg, zapLogger, requestRepo, resultRepo, queueClient)
 	case "api":
 		fallthrough
 	default:
-		runAPI(cfg, zapLogger, requestRepo, resultRepo, queueClient)
+		runAPI(cfg, zapLogger, requestRepo, resultRepo, pageRepo, outboxRepo, queueClient)
 	}
 }
 
@@ -79,6 +93,8 @@ func runAPI(
 	logger *zap.Logger,
 	requestRepo ports.RequestRepository,
 	resultRepo ports.ResultRepository,
+	pageRepo ports.PageRepository,
+	outboxRepo ports.OutboxRepository,
 	queueClient ports.QueueClient,
 ) {
 	// Create Fiber app
@@ -90,8 +106,24 @@ func runAPI(
 		ErrorHandler: customErrorHandler(logger),
 	})
 
+	// Initialize archive ingestion (CBZ, CBR/RAR, 7z, PDF, EPUB)
+	ingester := ingest.NewIngester(&cfg.Archive, logger)
+
+	// Publisher for lifecycle events sent by the API itself (e.g. cancellation)
+	publisher, err := pubsub.NewPublisher(&cfg.Redis, logger)
+	if err != nil {
+		logger.Fatal("failed to create publisher", zap.Error(err))
+	}
+	defer publisher.Close()
+
+	// Push the translation tasks saved with new requests to the queue
+	dispatchCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	dispatcher := application.NewOutboxDispatcher(outboxRepo, requestRepo, queueClient, cfg, logger)
+	go dispatcher.Run(dispatchCtx)
+
 	// Setup routes
-	httpAdapter.SetupRoutes(app, cfg, logger, requestRepo, resultRepo, queueClient)
+	httpAdapter.SetupRoutes(app, cfg, logger, requestRepo, resultRepo, pageRepo, queueClient, dispatcher, ingester, publisher)
 
 	// Start server in goroutine
 	go func() {
@@ -125,12 +157,30 @@ func runWorker(
 	logger *zap.Logger,
 	requestRepo ports.RequestRepository,
 	resultRepo ports.ResultRepository,
+	pageRepo ports.PageRepository,
+	queueClient ports.QueueClient,
 ) {
-	// Initialize Python executor
-	executor := python.NewPythonExecutor(&cfg.Worker, &cfg.Storage, logger)
+	// Initialize the worker executor: warm daemons, a remote worker service, the fake worker,
+	// or one process per job
+	var executor ports.WorkerExecutor
+	switch cfg.Worker.Mode {
+	case config.WorkerModeDaemon:
+		executor = python.NewDaemonExecutor(&cfg.Worker, &cfg.Storage, logger)
+	case config.WorkerModeRemote:
+		executor = remote.NewExecutor(&cfg.Worker, logger)
+	case config.WorkerModeFake:
+		executor = fake.NewExecutor(&cfg.Worker, logger)
+	default:
+		executor = python.NewPythonExecutor(&cfg.Worker, &cfg.Storage, logger)
+	}
+
+	// Storage retention policies and the reconciler, run by periodic tasks
+	requestService := application.NewRequestService(requestRepo, resultRepo, queueClient, nil, nil, cfg, logger)
+	cleanupService := application.NewCleanupService(requestRepo, requestService, cfg, logger)
+	reconcileService := application.NewReconcileService(requestRepo, queueClient, requestService, cfg, logger)
 
 	// Initialize queue server
-	queueServer := asynq.NewQueueServer(cfg, logger, requestRepo, resultRepo, executor)
+	queueServer := asynq.NewQueueServer(cfg, logger, requestRepo, resultRepo, pageRepo, executor, cleanupService, reconcileService)
 
 	// Start worker in goroutine
 	go func() {
@@ -140,16 +190,47 @@ func runWorker(
 		}
 	}()
 
+	// Periodically enqueue the storage cleanup and the reconciler
+	var scheduler ports.QueueServer
+	if cfg.Retention.Schedule != "" || cfg.Reconcile.Schedule != "" {
+		scheduler = asynq.NewScheduler(cfg, logger)
+		if err := scheduler.Start(); err != nil {
+			logger.Fatal("scheduler failed", zap.Error(err))
+		}
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("shutting down worker...")
+	if scheduler != nil {
+		scheduler.Stop()
+	}
 	queueServer.Stop()
+	if closer, ok := executor.(io.Closer); ok {
+		closer.Close()
+	}
 	logger.Info("worker stopped")
 }
 
+// runCleanup applies the storage retention policies once and exits
+func runCleanup(
+	cfg *config.Config,
+	logger *zap.Logger,
+	requestRepo ports.RequestRepository,
+	resultRepo ports.ResultRepository,
+	queueClient ports.QueueClient,
+) {
+	requestService := application.NewRequestService(requestRepo, resultRepo, queueClient, nil, nil, cfg, logger)
+	cleanupService := application.NewCleanupService(requestRepo, requestService, cfg, logger)
+
+	if _, err := cleanupService.Cleanup(context.Background()); err != nil {
+		logger.Fatal("storage cleanup failed", zap.Error(err))
+	}
+}
+
 func customErrorHandler(logger *zap.Logger) fiber.ErrorHandler {
 	return func(c *fiber.Ctx, err error) error {
 		code := fiber.StatusInternalServerError
@@ -0,0 +1,24 @@
+// Command stubworker serves a fake remote worker service that returns every page untouched,
+// so WORKER_MODE=remote can be exercised without a GPU.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/stub"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "Address to listen on")
+	token := flag.String("token", "", "Bearer token the requests must carry")
+	delay := flag.Duration("delay", 0, "Time spent on each page")
+	flag.Parse()
+
+	handler := stub.NewHandler(stub.Options{Token: *token, PageDelay: *delay})
+	log.Printf("stub worker service listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		log.Fatalf("stub worker service stopped: %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+// Package archive reads untrusted upload archives (ZIP, RAR, 7z) safely.
+// Every entry is checked for path traversal, links and special files, and
+// extraction is bounded by entry count, total uncompressed size and compression ratio.
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/bodgit/sevenzip"
+	"github.com/nwaples/rardecode/v2"
+)
+
+// Format identifies the container format of an archive
+type Format string
+
+const (
+	FormatZip Format = "zip"
+	FormatRAR Format = "rar"
+	Format7z  Format = "7z"
+)
+
+// Limits bounds what a single archive may expand to. Zero disables a limit.
+type Limits struct {
+	MaxEntries          int
+	MaxTotalSize        int64
+	MaxCompressionRatio float64
+}
+
+// NewLimits builds extraction limits from configuration
+func NewLimits(cfg *config.ArchiveConfig) Limits {
+	return Limits{
+		MaxEntries:          cfg.MaxEntries,
+		MaxTotalSize:        cfg.MaxUncompressedSize,
+		MaxCompressionRatio: cfg.MaxCompressionRatio,
+	}
+}
+
+// EntryFunc is called for every regular file of an archive, in archive order.
+// name is a cleaned, slash-separated relative path; r is bounded by the archive limits.
+type EntryFunc func(name string, r io.Reader) error
+
+// Walk validates an archive and calls fn for each regular file it contains.
+// ZIP and 7z headers are all validated before any content is read; RAR archives
+// are read sequentially and validated entry by entry.
+func Walk(ctx context.Context, srcPath string, format Format, limits Limits, fn EntryFunc) error {
+	if format == FormatZip {
+		return walkZip(ctx, srcPath, limits, fn)
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	g := newGuard(limits, info.Size())
+
+	switch format {
+	case FormatRAR:
+		return walkRar(ctx, srcPath, g, fn)
+	case Format7z:
+		return walk7z(ctx, srcPath, g, fn)
+	default:
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+// Extract writes the regular files accepted by keep into destDir and returns their relative names.
+// A nil keep extracts everything.
+func Extract(ctx context.Context, srcPath string, format Format, destDir string, limits Limits, keep func(name string) bool) ([]string, error) {
+	var names []string
+	err := Walk(ctx, srcPath, format, limits, func(name string, r io.Reader) error {
+		if keep != nil && !keep(name) {
+			return nil
+		}
+
+		destPath, err := SafeJoin(destDir, name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return err
+		}
+
+		// O_EXCL refuses to follow anything already sitting at the destination
+		out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// List validates the headers of a ZIP archive and returns the names of its regular files
+// without decompressing any content
+func List(srcPath string, limits Limits) ([]string, error) {
+	z, err := OpenZip(srcPath, limits)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	names := make([]string, 0, len(z.Files))
+	for name := range z.Files {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Zip is a ZIP archive whose headers passed validation.
+// Files maps cleaned entry names to regular files.
+type Zip struct {
+	Files  map[string]*zip.File
+	reader *zip.ReadCloser
+	guard  *guard
+}
+
+// OpenZip opens a ZIP archive for random access, validating every header upfront
+func OpenZip(srcPath string, limits Limits) (*Zip, error) {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	z := &Zip{
+		Files:  make(map[string]*zip.File, len(reader.File)),
+		reader: reader,
+		guard:  newGuard(limits, info.Size()),
+	}
+	for _, file := range reader.File {
+		name, err := z.guard.checkEntry(file.Name, file.Mode(), int64(file.UncompressedSize64))
+		if err == nil && name != "" {
+			err = z.guard.checkRatio(file.Name, int64(file.CompressedSize64), int64(file.UncompressedSize64))
+		}
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		if name != "" {
+			z.Files[name] = file
+		}
+	}
+
+	return z, nil
+}
+
+// Open returns a reader for one of the archive files, bounded by the archive limits
+func (z *Zip) Open(file *zip.File) (io.ReadCloser, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{z.guard.reader(rc), rc}, nil
+}
+
+// Close closes the underlying archive
+func (z *Zip) Close() error {
+	return z.reader.Close()
+}
+
+func walkZip(ctx context.Context, srcPath string, limits Limits, fn EntryFunc) error {
+	z, err := OpenZip(srcPath, limits)
+	if err != nil {
+		return err
+	}
+	defer z.Close()
+
+	for _, file := range z.reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name, _ := cleanName(file.Name)
+		if z.Files[name] != file {
+			continue
+		}
+
+		if err := readEntry(name, fn, func() (io.ReadCloser, error) { return z.Open(file) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkRar(ctx context.Context, srcPath string, g *guard, fn EntryFunc) error {
+	reader, err := rardecode.OpenReader(srcPath)
+	if err != nil {
+		return invalid(err)
+	}
+	defer reader.Close()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return invalid(err)
+		}
+
+		// Hard links, junctions and file copies are redirections too
+		mode := header.Mode()
+		if header.LinkType != 0 {
+			mode |= fs.ModeSymlink
+		}
+
+		declared := header.UnPackedSize
+		if header.UnKnownSize {
+			declared = 0
+		}
+		name, err := g.checkEntry(header.Name, mode, declared)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+
+		if err := fn(name, g.reader(reader)); err != nil {
+			return wrapRead(err)
+		}
+	}
+}
+
+func walk7z(ctx context.Context, srcPath string, g *guard, fn EntryFunc) error {
+	reader, err := sevenzip.OpenReader(srcPath)
+	if err != nil {
+		return invalid(err)
+	}
+	defer reader.Close()
+
+	names := make([]string, len(reader.File))
+	for idx, file := range reader.File {
+		name, err := g.checkEntry(file.Name, file.Mode(), int64(file.UncompressedSize))
+		if err != nil {
+			return err
+		}
+		names[idx] = name
+	}
+
+	// Read in archive order, which is fastest for solid blocks
+	for idx, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if names[idx] == "" {
+			continue
+		}
+
+		open := func() (io.ReadCloser, error) {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, invalid(err)
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{g.reader(rc), rc}, nil
+		}
+		if err := readEntry(names[idx], fn, open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEntry(name string, fn EntryFunc, open func() (io.ReadCloser, error)) error {
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return wrapRead(fn(name, rc))
+}
+
+// wrapRead keeps limit rejections as they are and reports other read failures as invalid archives
+func wrapRead(err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return invalid(err)
+}
+
+func invalid(err error) error {
+	return domain.NewAppError(domain.CodeInvalidArchive, "failed to read archive", fmt.Errorf("%w: %v", domain.ErrInvalidArchive, err))
+}
@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+)
+
+// ratioFloor is the amount of extracted data below which the compression ratio is not enforced,
+// so small archives of highly compressible files are not rejected
+const ratioFloor = 1 << 20 // 1MB
+
+// guard enforces Limits over all entries of one archive
+type guard struct {
+	limits      Limits
+	archiveSize int64
+	entries     int
+	declared    int64 // sum of declared uncompressed sizes
+	extracted   int64 // uncompressed bytes actually read
+}
+
+func newGuard(limits Limits, archiveSize int64) *guard {
+	return &guard{limits: limits, archiveSize: archiveSize}
+}
+
+// checkEntry validates an entry header and returns its cleaned name.
+// Directories are not counted and return an empty name.
+func (g *guard) checkEntry(name string, mode fs.FileMode, declaredSize int64) (string, error) {
+	if mode&fs.ModeSymlink != 0 {
+		return "", rejection(domain.CodeArchiveSymlink, fmt.Sprintf("archive entry %q is a symbolic link", name))
+	}
+
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if mode.IsDir() {
+		return "", nil
+	}
+	if !mode.IsRegular() {
+		return "", rejection(domain.CodeArchiveUnsupportedEntry, fmt.Sprintf("archive entry %q is not a regular file", name))
+	}
+
+	g.entries++
+	if g.limits.MaxEntries > 0 && g.entries > g.limits.MaxEntries {
+		return "", rejection(domain.CodeArchiveTooManyEntries,
+			fmt.Sprintf("archive has more than %d entries", g.limits.MaxEntries))
+	}
+
+	g.declared += declaredSize
+	if g.limits.MaxTotalSize > 0 && g.declared > g.limits.MaxTotalSize {
+		return "", rejection(domain.CodeArchiveTooLarge,
+			fmt.Sprintf("archive expands to more than %d bytes", g.limits.MaxTotalSize))
+	}
+
+	return cleaned, nil
+}
+
+// checkRatio rejects a single entry whose declared compression ratio is suspicious
+func (g *guard) checkRatio(name string, compressed, uncompressed int64) error {
+	if g.limits.MaxCompressionRatio <= 0 || uncompressed < ratioFloor {
+		return nil
+	}
+	if compressed <= 0 || float64(uncompressed)/float64(compressed) > g.limits.MaxCompressionRatio {
+		return rejection(domain.CodeArchiveCompressionRatio,
+			fmt.Sprintf("archive entry %q exceeds compression ratio %.0f", name, g.limits.MaxCompressionRatio))
+	}
+	return nil
+}
+
+// reader bounds the content of an entry by the real number of bytes decompressed,
+// since declared sizes in headers can be forged
+func (g *guard) reader(r io.Reader) io.Reader {
+	return &guardedReader{r: r, g: g}
+}
+
+type guardedReader struct {
+	r io.Reader
+	g *guard
+}
+
+func (gr *guardedReader) Read(p []byte) (int, error) {
+	n, err := gr.r.Read(p)
+	gr.g.extracted += int64(n)
+
+	limits := gr.g.limits
+	if limits.MaxTotalSize > 0 && gr.g.extracted > limits.MaxTotalSize {
+		return n, rejection(domain.CodeArchiveTooLarge,
+			fmt.Sprintf("archive expands to more than %d bytes", limits.MaxTotalSize))
+	}
+	if limits.MaxCompressionRatio > 0 && gr.g.archiveSize > 0 && gr.g.extracted > ratioFloor &&
+		float64(gr.g.extracted)/float64(gr.g.archiveSize) > limits.MaxCompressionRatio {
+		return n, rejection(domain.CodeArchiveCompressionRatio,
+			fmt.Sprintf("archive exceeds compression ratio %.0f", limits.MaxCompressionRatio))
+	}
+
+	return n, err
+}
+
+// cleanName normalises an entry name to a slash-separated relative path and
+// rejects names that would resolve outside the extraction directory
+func cleanName(name string) (string, error) {
+	normalised := strings.ReplaceAll(name, "\\", "/")
+	cleaned := path.Clean(normalised)
+
+	if strings.HasPrefix(normalised, "/") || filepath.VolumeName(normalised) != "" ||
+		strings.Contains(cleaned, ":") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", rejection(domain.CodeArchivePathEscape, fmt.Sprintf("archive entry %q escapes the destination directory", name))
+	}
+
+	return strings.TrimPrefix(cleaned, "./"), nil
+}
+
+// SafeJoin joins an entry name onto destDir, guaranteeing the result stays inside destDir
+func SafeJoin(destDir, name string) (string, error) {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+
+	destPath := filepath.Join(destDir, filepath.FromSlash(cleaned))
+	rel, err := filepath.Rel(destDir, destPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", rejection(domain.CodeArchivePathEscape, fmt.Sprintf("archive entry %q escapes the destination directory", name))
+	}
+
+	return destPath, nil
+}
+
+func rejection(code, message string) error {
+	return domain.NewAppError(code, message, domain.ErrInvalidArchive)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AdminHandler serves operational endpoints under /api/admin
+type AdminHandler struct {
+	requestService   *application.RequestService
+	reconcileService *application.ReconcileService
+	logger           *zap.Logger
+}
+
+func NewAdminHandler(
+	requestService *application.RequestService,
+	reconcileService *application.ReconcileService,
+	logger *zap.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		requestService:   requestService,
+		reconcileService: reconcileService,
+		logger:           logger,
+	}
+}
+
+// priorityBody is the body of PUT /api/admin/requests/:id/priority
+type priorityBody struct {
+	Priority string `json:"priority"`
+}
+
+// SetPriority handles PUT /api/admin/requests/:id/priority
+func (h *AdminHandler) SetPriority(c *fiber.Ctx) error {
+	// Parse ID
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request ID",
+		})
+	}
+
+	var body priorityBody
+	if err := c.BodyParser(&body); err != nil || body.Priority == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "priority is required",
+		})
+	}
+
+	priority, err := domain.ParsePriority(body.Priority)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+	}
+
+	request, err := h.requestService.SetPriority(c.Context(), id, priority)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "request not found",
+			})
+		case errors.Is(err, domain.ErrInvalidState):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "only queued requests can change priority",
+			})
+		}
+		h.logger.Error("failed to set priority", zap.Error(err), zap.String("id", idStr))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to set priority",
+		})
+	}
+
+	return c.JSON(request)
+}
+
+// InspectReconcile handles GET /api/admin/reconcile.
+// It reports the requests the reconciler would act on without changing anything.
+func (h *AdminHandler) InspectReconcile(c *fiber.Ctx) error {
+	return h.reconcile(c, true)
+}
+
+// Reconcile handles POST /api/admin/reconcile.
+// It runs the reconciler now instead of waiting for its schedule.
+func (h *AdminHandler) Reconcile(c *fiber.Ctx) error {
+	return h.reconcile(c, false)
+}
+
+func (h *AdminHandler) reconcile(c *fiber.Ctx, dryRun bool) error {
+	report, err := h.reconcileService.Reconcile(c.Context(), dryRun)
+	if err != nil {
+		h.logger.Error("failed to reconcile requests", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to reconcile requests",
+		})
+	}
+
+	return c.JSON(report)
+}
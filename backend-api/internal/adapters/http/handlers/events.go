@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
@@ -19,24 +20,29 @@ import (
 
 type EventsHandler struct {
 	requestRepo ports.RequestRepository
+	queueClient ports.QueueClient
 	cfg         *config.Config
 	logger      *zap.Logger
 }
 
 func NewEventsHandler(
 	requestRepo ports.RequestRepository,
+	queueClient ports.QueueClient,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *EventsHandler {
 	return &EventsHandler{
 		requestRepo: requestRepo,
+		queueClient: queueClient,
 		cfg:         cfg,
 		logger:      logger,
 	}
 }
 
 // StreamProgress handles GET /api/requests/:id/events
-// Streams progress updates via Server-Sent Events (SSE)
+// Streams progress updates via Server-Sent Events (SSE), page re-translations included.
+// A completed request is followed until none of its pages is being re-translated.
+// With ?page=N it follows the re-translation of that page of a completed request only.
 func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 	// Parse request ID
 	idStr := c.Params("id")
@@ -47,6 +53,13 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 		})
 	}
 
+	page := c.QueryInt("page")
+	if page < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid page number",
+		})
+	}
+
 	// Verify request exists
 	request, err := h.requestRepo.GetByID(c.Context(), requestID)
 	if err != nil {
@@ -67,13 +80,16 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 	c.Set("Connection", "keep-alive")
 	c.Set("Transfer-Encoding", "chunked")
 
-	// If already completed, send final status and close
-	if request.IsCompleted() {
-		h.sendSSEEvent(c, "complete", fiber.Map{
-			"status":   request.Status,
-			"progress": request.Progress,
-			"message":  fmt.Sprintf("Translation %s", request.Status),
-		})
+	// If already completed, send final status and close, unless a page of it is followed or
+	// being re-translated
+	followPage := page > 0 && request.Status.HasResults()
+	if !followPage {
+		page = 0
+	}
+	followPages := page == 0 && request.Status.HasResults() && h.pagesRetranslating(c.Context(), request)
+	if request.IsCompleted() && !followPage && !followPages {
+		eventType, data := finalEvent(request)
+		h.sendSSEEvent(c, eventType, data)
 		return nil
 	}
 
@@ -122,6 +138,12 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 			return
 		}
 
+		// The last page may have finished before the subscription started
+		if followPages && !h.pagesRetranslating(ctx, request) {
+			h.writeFinalEvent(ctx, w, request)
+			return
+		}
+
 		// Keep-alive ticker
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
@@ -143,6 +165,11 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 					return
 				}
 
+				// Page streams only carry their page, except for a cancellation which ends them
+				if page > 0 && update.Page != page && update.Status != string(domain.StatusCancelled) {
+					continue
+				}
+
 				h.logger.Debug("SSE received update",
 					zap.String("request_id", requestID.String()),
 					zap.Int("progress", update.Progress),
@@ -151,10 +178,18 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 
 				// Determine event type
 				eventType := "progress"
-				if update.Status == string(domain.StatusCompleted) {
+				if update.Status == string(domain.StatusCompleted) || update.Status == string(domain.StatusPartial) {
 					eventType = "complete"
 				} else if update.Status == string(domain.StatusFailed) {
 					eventType = "error"
+				} else if update.Status == string(domain.StatusCancelled) {
+					eventType = "cancelled"
+				} else if update.Status == string(domain.StatusRetrying) {
+					// Not final: the next attempt reports progress on the same stream
+					eventType = "retrying"
+				}
+				if update.Page > 0 {
+					eventType = "page_" + eventType
 				}
 
 				// Send SSE event
@@ -163,6 +198,15 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 					"progress": update.Progress,
 					"message":  update.Message,
 				}
+				if update.Page > 0 {
+					data["page"] = update.Page
+				}
+				if update.Attempt > 0 {
+					data["attempt"] = update.Attempt
+				}
+				if update.NextAttemptAt != nil {
+					data["nextAttemptAt"] = update.NextAttemptAt
+				}
 
 				if err := h.writeSSEEvent(w, eventType, data); err != nil {
 					h.logger.Error("failed to write SSE event", zap.Error(err))
@@ -175,8 +219,19 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 					zap.Int("progress", update.Progress),
 				)
 
-				// If completed or failed, close the stream
-				if eventType == "complete" || eventType == "error" {
+				// Close the stream once the request, or the page it follows, completed, failed or
+				// was cancelled. A completed request is followed until its last page finished.
+				ended := strings.HasSuffix(eventType, "complete") || strings.HasSuffix(eventType, "error") || eventType == "cancelled"
+				switch {
+				case !ended:
+				case followPages && eventType != "error" && eventType != "cancelled":
+					if !h.pagesRetranslating(ctx, request) {
+						if update.Page > 0 {
+							h.writeFinalEvent(ctx, w, request)
+						}
+						return
+					}
+				default:
 					return
 				}
 
@@ -193,6 +248,41 @@ func (h *EventsHandler) StreamProgress(c *fiber.Ctx) error {
 	return nil
 }
 
+// pagesRetranslating reports whether a page of a request is waiting for or being re-translated
+func (h *EventsHandler) pagesRetranslating(ctx context.Context, request *domain.Request) bool {
+	pending, err := h.queueClient.HasPageTranslation(ctx, request.ID, request.PageCount)
+	if err != nil {
+		h.logger.Warn("failed to look up page re-translations",
+			zap.String("request_id", request.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	return pending
+}
+
+// writeFinalEvent writes the current final status of a request whose pages were followed
+func (h *EventsHandler) writeFinalEvent(ctx context.Context, w *bufio.Writer, request *domain.Request) {
+	if current, err := h.requestRepo.GetByID(ctx, request.ID); err == nil {
+		request = current
+	}
+	eventType, data := finalEvent(request)
+	h.writeSSEEvent(w, eventType, data)
+}
+
+// finalEvent is the event telling a client that a request is finished
+func finalEvent(request *domain.Request) (string, fiber.Map) {
+	eventType := "complete"
+	if request.Status == domain.StatusCancelled {
+		eventType = "cancelled"
+	}
+	return eventType, fiber.Map{
+		"status":   request.Status,
+		"progress": request.Progress,
+		"message":  fmt.Sprintf("Translation %s", request.Status),
+	}
+}
+
 // sendSSEEvent sends an SSE event via Fiber context
 func (h *EventsHandler) sendSSEEvent(c *fiber.Ctx, event string, data fiber.Map) error {
 	jsonData, err := json.Marshal(data)
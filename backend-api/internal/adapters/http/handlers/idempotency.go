@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// headerIdempotencyKey lets clients retry an upload without creating duplicate requests
+	headerIdempotencyKey = "Idempotency-Key"
+
+	// headerIdempotentReplayed marks a response returned for a repeated Idempotency-Key
+	headerIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// parseIdempotencyKey returns the canonical form of an Idempotency-Key. Keys are not scoped
+// to a client, so only UUIDs are accepted: two clients generating them never pick the same one.
+func parseIdempotencyKey(key string) (string, bool) {
+	id, err := uuid.Parse(key)
+	if err != nil || len(key) != 36 {
+		return "", false
+	}
+	return id.String(), true
+}
+
+// uploadFingerprint hashes everything that decides which requests an upload creates:
+// the mode, options and priority, and the name and content of every file in order
+func uploadFingerprint(mode string, params uploadParams, files []*multipart.FileHeader) (string, error) {
+	hash := sha256.New()
+
+	settings, err := json.Marshal(struct {
+		Mode     string                    `json:"mode"`
+		Options  domain.TranslationOptions `json:"options"`
+		Priority domain.Priority           `json:"priority"`
+	}{mode, params.options, params.priority})
+	if err != nil {
+		return "", err
+	}
+	hash.Write(settings)
+
+	for _, file := range files {
+		fmt.Fprintf(hash, "\x00%s\x00%d\x00", file.Filename, file.Size)
+
+		src, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(hash, src)
+		src.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// lockIdempotencyKey reserves a key for the upload in progress, returning false if
+// another upload with the same key is still running
+func (h *UploadHandler) lockIdempotencyKey(key string) bool {
+	h.keyMu.Lock()
+	defer h.keyMu.Unlock()
+
+	if h.keyLocks[key] {
+		return false
+	}
+	h.keyLocks[key] = true
+	return true
+}
+
+func (h *UploadHandler) unlockIdempotencyKey(key string) {
+	h.keyMu.Lock()
+	defer h.keyMu.Unlock()
+	delete(h.keyLocks, key)
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/gofiber/fiber/v2"
@@ -10,15 +12,24 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxBulkDelete is the maximum number of requests deleted in one call
+const maxBulkDelete = 100
+
 type RequestsHandler struct {
-	requestRepo ports.RequestRepository
-	logger      *zap.Logger
+	requestRepo    ports.RequestRepository
+	requestService *application.RequestService
+	logger         *zap.Logger
 }
 
-func NewRequestsHandler(requestRepo ports.RequestRepository, logger *zap.Logger) *RequestsHandler {
+func NewRequestsHandler(
+	requestRepo ports.RequestRepository,
+	requestService *application.RequestService,
+	logger *zap.Logger,
+) *RequestsHandler {
 	return &RequestsHandler{
-		requestRepo: requestRepo,
-		logger:      logger,
+		requestRepo:    requestRepo,
+		requestService: requestService,
+		logger:         logger,
 	}
 }
 
@@ -34,6 +45,14 @@ func (h *RequestsHandler) List(c *fiber.Ctx) error {
 		filter.Status = &status
 	}
 
+	if priorityStr := c.Query("priority"); priorityStr != "" {
+		priority, err := domain.ParsePriority(priorityStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+		}
+		filter.Priority = &priority
+	}
+
 	// Get requests from repository
 	requests, total, err := h.requestRepo.List(c.Context(), filter)
 	if err != nil {
@@ -78,3 +97,175 @@ func (h *RequestsHandler) GetByID(c *fiber.Ctx) error {
 
 	return c.JSON(request)
 }
+
+// Cancel handles POST /api/requests/:id/cancel
+func (h *RequestsHandler) Cancel(c *fiber.Ctx) error {
+	// Parse ID
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request ID",
+		})
+	}
+
+	request, err := h.requestService.Cancel(c.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "request not found",
+			})
+		case errors.Is(err, domain.ErrInvalidState):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "request can no longer be cancelled",
+			})
+		}
+		h.logger.Error("failed to cancel request", zap.Error(err), zap.String("id", idStr))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to cancel request",
+		})
+	}
+
+	return c.JSON(request)
+}
+
+// optionsBody is the optional body of the endpoints that translate a request again
+type optionsBody struct {
+	Options *domain.TranslationOptions `json:"options"`
+}
+
+// Retry handles POST /api/requests/:id/retry
+func (h *RequestsHandler) Retry(c *fiber.Ctx) error {
+	// Parse ID
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request ID",
+		})
+	}
+
+	var body optionsBody
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	request, err := h.requestService.Retry(c.Context(), id, body.Options)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "request not found",
+			})
+		case errors.Is(err, domain.ErrInvalidState):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "request is still queued or processing",
+			})
+		case errors.Is(err, domain.ErrUploadGone):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "original upload is no longer available",
+			})
+		case errors.Is(err, domain.ErrInvalidInput):
+			return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+		}
+		h.logger.Error("failed to retry request", zap.Error(err), zap.String("id", idStr))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to retry request",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(request)
+}
+
+// Delete handles DELETE /api/requests/:id
+func (h *RequestsHandler) Delete(c *fiber.Ctx) error {
+	// Parse ID
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request ID",
+		})
+	}
+
+	if err := h.requestService.Delete(c.Context(), id, c.QueryBool("force")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "request not found",
+			})
+		case errors.Is(err, domain.ErrInvalidState):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "request is processing, set force=true to delete it anyway",
+			})
+		}
+		h.logger.Error("failed to delete request", zap.Error(err), zap.String("id", idStr))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete request",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// bulkDeleteBody is the body of DELETE /api/requests
+type bulkDeleteBody struct {
+	IDs   []uuid.UUID `json:"ids"`
+	Force bool        `json:"force"`
+}
+
+// deleteError reports why a single request of a bulk delete was not deleted
+type deleteError struct {
+	ID    uuid.UUID `json:"id"`
+	Error string    `json:"error"`
+}
+
+// BulkDelete handles DELETE /api/requests
+func (h *RequestsHandler) BulkDelete(c *fiber.Ctx) error {
+	var body bulkDeleteBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if len(body.IDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "no request IDs provided",
+		})
+	}
+
+	if len(body.IDs) > maxBulkDelete {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("too many request IDs (max %d)", maxBulkDelete),
+		})
+	}
+
+	// Delete every request, collecting failures instead of stopping at the first one
+	deleted := []uuid.UUID{}
+	deleteErrors := []deleteError{}
+	for _, id := range body.IDs {
+		err := h.requestService.Delete(c.Context(), id, body.Force)
+		switch {
+		case err == nil:
+			deleted = append(deleted, id)
+		case errors.Is(err, domain.ErrNotFound):
+			deleteErrors = append(deleteErrors, deleteError{ID: id, Error: "request not found"})
+		case errors.Is(err, domain.ErrInvalidState):
+			deleteErrors = append(deleteErrors, deleteError{ID: id, Error: "request is processing"})
+		default:
+			h.logger.Error("failed to delete request", zap.Error(err), zap.String("id", id.String()))
+			deleteErrors = append(deleteErrors, deleteError{ID: id, Error: "failed to delete request"})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"deleted": deleted,
+		"errors":  deleteErrors,
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/gofiber/fiber/v2"
@@ -11,20 +12,26 @@ import (
 )
 
 type ResultsHandler struct {
-	requestRepo ports.RequestRepository
-	resultRepo  ports.ResultRepository
-	logger      *zap.Logger
+	requestRepo    ports.RequestRepository
+	resultRepo     ports.ResultRepository
+	pageRepo       ports.PageRepository
+	requestService *application.RequestService
+	logger         *zap.Logger
 }
 
 func NewResultsHandler(
 	requestRepo ports.RequestRepository,
 	resultRepo ports.ResultRepository,
+	pageRepo ports.PageRepository,
+	requestService *application.RequestService,
 	logger *zap.Logger,
 ) *ResultsHandler {
 	return &ResultsHandler{
-		requestRepo: requestRepo,
-		resultRepo:  resultRepo,
-		logger:      logger,
+		requestRepo:    requestRepo,
+		resultRepo:     resultRepo,
+		pageRepo:       pageRepo,
+		requestService: requestService,
+		logger:         logger,
 	}
 }
 
@@ -69,8 +76,73 @@ func (h *ResultsHandler) GetByRequestID(c *fiber.Ctx) error {
 		})
 	}
 
+	// Status of every page as the worker reported it, including pages without a result
+	pages, err := h.pageRepo.GetByRequestID(c.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get pages", zap.Error(err), zap.String("requestId", idStr))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to retrieve results",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"requestId": id,
-		"pages":     results,
+		"requestId":  id,
+		"status":     request.Status,
+		"pages":      results,
+		"pageStatus": pages,
 	})
 }
+
+// RetranslatePage handles POST /api/results/:id/pages/:page/retranslate
+func (h *ResultsHandler) RetranslatePage(c *fiber.Ctx) error {
+	// Parse ID
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request ID",
+		})
+	}
+
+	page, err := c.ParamsInt("page")
+	if err != nil || page < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid page number",
+		})
+	}
+
+	var body optionsBody
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	result, err := h.requestService.RetranslatePage(c.Context(), id, page, body.Options)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "request or page not found",
+			})
+		case errors.Is(err, domain.ErrInvalidState):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "request is not completed or the page is already being re-translated",
+			})
+		case errors.Is(err, domain.ErrUploadGone):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "original page is no longer available",
+			})
+		case errors.Is(err, domain.ErrInvalidInput):
+			return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+		}
+		h.logger.Error("failed to retranslate page", zap.Error(err), zap.String("id", idStr), zap.Int("page", page))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to retranslate page",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(result)
+}
@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Resumable uploads follow the tus 1.0.0 core protocol with the creation and
+// termination extensions (https://tus.io/protocols/resumable-upload).
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination"
+
+	// tusInfoFile holds the upload state next to the partial data in storage/uploads/<id>
+	tusInfoFile = "upload.json"
+	// tusPartSuffix marks data that is still being uploaded
+	tusPartSuffix = ".part"
+)
+
+// tusUpload is the persisted state of a resumable upload
+type tusUpload struct {
+	ID        uuid.UUID         `json:"id"`
+	Filename  string            `json:"filename"`
+	Length    int64             `json:"length"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	Completed bool              `json:"completed"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// TusOptions handles OPTIONS /api/uploads
+func (h *UploadHandler) TusOptions(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(h.cfg.Storage.MaxResumableUploadSize, 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TusCreate handles POST /api/uploads
+// Creates a new upload resource; the translation request is only created once all bytes arrived.
+func (h *UploadHandler) TusCreate(c *fiber.Ctx) error {
+	if err := h.checkTusVersion(c); err != nil {
+		return err
+	}
+
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "missing or invalid Upload-Length header",
+		})
+	}
+
+	if length > h.cfg.Storage.MaxResumableUploadSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "file too large",
+		})
+	}
+
+	metadata, err := parseTusMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid Upload-Metadata header",
+		})
+	}
+
+	filename := filepath.Base(metadata["filename"])
+	if metadata["filename"] == "" || filename == "." || filename == string(filepath.Separator) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "filename metadata is required",
+		})
+	}
+
+	if _, ok := h.getFileType(filename); !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "unsupported file type",
+		})
+	}
+
+	// Translation options travel as metadata and are applied once the upload completes
+	if _, err := parseTranslationOptions(func(key string) string { return metadata[key] }); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+	}
+	if _, err := domain.ParsePriority(metadata["priority"]); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+	}
+
+	upload := &tusUpload{
+		ID:        uuid.New(),
+		Filename:  filename,
+		Length:    length,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+
+	uploadDir := h.tusDir(upload.ID)
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		h.logger.Error("failed to create upload directory", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create upload",
+		})
+	}
+
+	if err := os.WriteFile(h.tusPartPath(upload), nil, 0644); err != nil {
+		h.logger.Error("failed to create upload file", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create upload",
+		})
+	}
+
+	if err := h.saveTusUpload(upload); err != nil {
+		h.logger.Error("failed to save upload state", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create upload",
+		})
+	}
+
+	h.logger.Info("resumable upload created",
+		zap.String("uploadId", upload.ID.String()),
+		zap.String("filename", filename),
+		zap.Int64("length", length),
+	)
+
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Location", fmt.Sprintf("%s/%s", strings.TrimSuffix(c.OriginalURL(), "/"), upload.ID))
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// TusHead handles HEAD /api/uploads/:id
+// Reports how many bytes the server has so the client can resume from there.
+func (h *UploadHandler) TusHead(c *fiber.Ctx) error {
+	if err := h.checkTusVersion(c); err != nil {
+		return err
+	}
+
+	upload, err := h.loadTusUpload(c.Params("id"))
+	if err != nil {
+		return h.tusLoadError(c, err)
+	}
+
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Cache-Control", "no-store")
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// TusPatch handles PATCH /api/uploads/:id
+// Appends a chunk at Upload-Offset. The last chunk creates and enqueues the translation request.
+func (h *UploadHandler) TusPatch(c *fiber.Ctx) error {
+	if err := h.checkTusVersion(c); err != nil {
+		return err
+	}
+
+	if c.Get("Content-Type") != "application/offset+octet-stream" {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "expected Content-Type application/offset+octet-stream",
+		})
+	}
+
+	id := c.Params("id")
+	if !h.lockTusUpload(id) {
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+			"error": "upload is being written by another request",
+		})
+	}
+	defer h.unlockTusUpload(id)
+
+	upload, err := h.loadTusUpload(id)
+	if err != nil {
+		return h.tusLoadError(c, err)
+	}
+
+	if upload.Completed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "upload already completed",
+		})
+	}
+
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset != upload.Offset {
+		c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload-Offset does not match current offset",
+		})
+	}
+
+	chunk := c.Body()
+	if upload.Offset+int64(len(chunk)) > upload.Length {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "chunk exceeds Upload-Length",
+		})
+	}
+
+	if len(chunk) > 0 {
+		if err := writeChunk(h.tusPartPath(upload), upload.Offset, chunk); err != nil {
+			h.logger.Error("failed to write upload chunk",
+				zap.String("uploadId", id),
+				zap.Error(err),
+			)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to write chunk",
+			})
+		}
+
+		upload.Offset += int64(len(chunk))
+		if err := h.saveTusUpload(upload); err != nil {
+			h.logger.Error("failed to save upload state", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to write chunk",
+			})
+		}
+	}
+
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+
+	if upload.Offset < upload.Length {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	// All bytes received: hand the file over to the translation pipeline.
+	// A failure here leaves the upload incomplete so an empty PATCH can finish it later.
+	if err := h.finishTusUpload(c, upload); err != nil {
+		h.logger.Error("failed to finish resumable upload",
+			zap.String("uploadId", id),
+			zap.Error(err),
+		)
+		var appErr *domain.AppError
+		if errors.As(err, &appErr) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": appErr.Message,
+				"code":  appErr.Code,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create request",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TusDelete handles DELETE /api/uploads/:id
+// Terminates an unfinished upload and removes its data.
+func (h *UploadHandler) TusDelete(c *fiber.Ctx) error {
+	if err := h.checkTusVersion(c); err != nil {
+		return err
+	}
+
+	id := c.Params("id")
+	if !h.lockTusUpload(id) {
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+			"error": "upload is being written by another request",
+		})
+	}
+	defer h.unlockTusUpload(id)
+
+	upload, err := h.loadTusUpload(id)
+	if err != nil {
+		return h.tusLoadError(c, err)
+	}
+
+	if upload.Completed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "upload already completed",
+		})
+	}
+
+	if err := os.RemoveAll(h.tusDir(upload.ID)); err != nil {
+		h.logger.Error("failed to delete upload", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete upload",
+		})
+	}
+
+	c.Set("Tus-Resumable", tusVersion)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// finishTusUpload creates and enqueues the request of a completed upload, then marks the
+// upload completed. The request reuses the upload ID so its files stay in storage/uploads/<id>,
+// and a finish that created it but failed to mark the upload completed can be run again.
+func (h *UploadHandler) finishTusUpload(c *fiber.Ctx, upload *tusUpload) error {
+	request, err := h.requestRepo.GetByID(c.Context(), upload.ID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		if request, err = h.createTusRequest(c.Context(), upload); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("failed to look up request: %w", err)
+	}
+
+	upload.Completed = true
+	if err := h.saveTusUpload(upload); err != nil {
+		return fmt.Errorf("failed to mark upload completed: %w", err)
+	}
+
+	c.Set("Upload-Request-Id", request.ID.String())
+
+	h.logger.Info("resumable upload completed",
+		zap.String("requestId", request.ID.String()),
+		zap.String("filename", upload.Filename),
+		zap.Int64("size", upload.Length),
+		zap.Int("pages", request.PageCount),
+	)
+
+	return nil
+}
+
+// createTusRequest moves the completed data in place, then creates and enqueues its request
+func (h *UploadHandler) createTusRequest(ctx context.Context, upload *tusUpload) (*domain.Request, error) {
+	filePath := filepath.Join(h.tusDir(upload.ID), upload.Filename)
+	if err := os.Rename(h.tusPartPath(upload), filePath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to finalize upload file: %w", err)
+	}
+
+	options, err := parseTranslationOptions(func(key string) string { return upload.Metadata[key] })
+	if err != nil {
+		return nil, err
+	}
+	priority, err := domain.ParsePriority(upload.Metadata["priority"])
+	if err != nil {
+		return nil, err
+	}
+
+	fileType, _ := h.getFileType(upload.Filename)
+	request := domain.NewRequest(upload.Filename, fileType)
+	request.ID = upload.ID
+	request.Options = options
+	request.Priority = priority
+
+	filePath, err = h.preparePages(ctx, request, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.submit(ctx, request, filePath); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (h *UploadHandler) checkTusVersion(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "unsupported tus version",
+		})
+	}
+	return nil
+}
+
+func (h *UploadHandler) tusLoadError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "upload not found",
+		})
+	}
+	h.logger.Error("failed to load upload state", zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "failed to load upload",
+	})
+}
+
+func (h *UploadHandler) tusDir(id uuid.UUID) string {
+	return filepath.Join(h.cfg.Storage.Path, "uploads", id.String())
+}
+
+func (h *UploadHandler) tusPartPath(upload *tusUpload) string {
+	return filepath.Join(h.tusDir(upload.ID), upload.Filename+tusPartSuffix)
+}
+
+func (h *UploadHandler) loadTusUpload(idStr string) (*tusUpload, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.tusDir(id), tusInfoFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	var upload tusUpload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("failed to decode upload state: %w", err)
+	}
+	return &upload, nil
+}
+
+// saveTusUpload writes the upload state atomically so a crash never leaves a torn file
+func (h *UploadHandler) saveTusUpload(upload *tusUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	infoPath := filepath.Join(h.tusDir(upload.ID), tusInfoFile)
+	tmpPath := infoPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, infoPath)
+}
+
+func (h *UploadHandler) lockTusUpload(id string) bool {
+	h.tusMu.Lock()
+	defer h.tusMu.Unlock()
+
+	if h.tusLocks[id] {
+		return false
+	}
+	h.tusLocks[id] = true
+	return true
+}
+
+func (h *UploadHandler) unlockTusUpload(id string) {
+	h.tusMu.Lock()
+	defer h.tusMu.Unlock()
+	delete(h.tusLocks, id)
+}
+
+// writeChunk writes data at the given offset and syncs it before the offset is advanced
+func writeChunk(path string, offset int64, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteAt(data, offset); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// parseTusMetadata decodes an Upload-Metadata header ("key base64value,key2 base64value2")
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		switch len(parts) {
+		case 1:
+			metadata[parts[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			metadata[parts[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("malformed metadata pair %q", pair)
+		}
+	}
+
+	return metadata, nil
+}
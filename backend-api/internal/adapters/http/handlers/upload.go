@@ -2,10 +2,20 @@ package handlers
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
@@ -13,24 +23,74 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// maxUploadFiles is the maximum number of files accepted in one upload
+	maxUploadFiles = 10
+
+	// Upload modes selected with the "mode" form field
+	uploadModeCombined = "combined" // all images become pages of one request
+	uploadModeSeparate = "separate" // every file becomes its own request
+
+	// batchArchiveName is the archive built from the images of a combined upload
+	batchArchiveName = "pages.zip"
+)
+
 type UploadHandler struct {
 	requestRepo ports.RequestRepository
-	queueClient ports.QueueClient
+	dispatcher  *application.OutboxDispatcher
+	ingester    ports.Ingester
 	cfg         *config.Config
 	logger      *zap.Logger
+
+	// tusLocks guards resumable uploads against concurrent PATCH requests
+	tusMu    sync.Mutex
+	tusLocks map[string]bool
+
+	// keyLocks holds the Idempotency-Keys of the uploads in progress
+	keyMu    sync.Mutex
+	keyLocks map[string]bool
+}
+
+// uploadParams are the settings shared by every request created by one upload
+type uploadParams struct {
+	options        domain.TranslationOptions
+	priority       domain.Priority
+	idempotencyKey string
+	fingerprint    string
+}
+
+// apply copies the upload settings onto a new request, the seq-th one created by the upload
+func (p uploadParams) apply(request *domain.Request, seq int) {
+	request.Options = p.options
+	request.Priority = p.priority
+	if p.idempotencyKey != "" {
+		request.IdempotencyKey = &p.idempotencyKey
+		request.IdempotencyHash = &p.fingerprint
+		request.IdempotencySeq = &seq
+	}
+}
+
+// fileError reports why a single uploaded file was rejected
+type fileError struct {
+	Filename string `json:"filename"`
+	Error    string `json:"error"`
 }
 
 func NewUploadHandler(
 	requestRepo ports.RequestRepository,
-	queueClient ports.QueueClient,
+	dispatcher *application.OutboxDispatcher,
+	ingester ports.Ingester,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *UploadHandler {
 	return &UploadHandler{
 		requestRepo: requestRepo,
-		queueClient: queueClient,
+		dispatcher:  dispatcher,
+		ingester:    ingester,
 		cfg:         cfg,
 		logger:      logger,
+		tusLocks:    make(map[string]bool),
+		keyLocks:    make(map[string]bool),
 	}
 }
 
@@ -51,116 +111,375 @@ func (h *UploadHandler) Upload(c *fiber.Ctx) error {
 		})
 	}
 
-	if len(files) > 10 {
+	if len(files) > maxUploadFiles {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "too many files (max 10)",
+			"error": fmt.Sprintf("too many files (max %d)", maxUploadFiles),
 		})
 	}
 
-	// Process first file (simplified for now)
-	file := files[0]
-
-	// Validate file size
-	if file.Size > h.cfg.Storage.MaxUploadSize {
+	mode := c.FormValue("mode", uploadModeCombined)
+	if mode != uploadModeCombined && mode != uploadModeSeparate {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "file too large",
+			"error": "invalid upload mode (expected combined or separate)",
 		})
 	}
 
-	// Validate file type
-	filename := file.Filename
-	fileType := h.getFileType(filename)
-	if fileType == "" {
+	options, err := parseTranslationOptions(func(key string) string { return c.FormValue(key) })
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+	}
+
+	priority, err := domain.ParsePriority(c.FormValue("priority"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(optionsError(err))
+	}
+	params := uploadParams{options: options, priority: priority}
+
+	// A repeated upload with the same Idempotency-Key returns the requests it created.
+	// The lock only covers this instance; the unique index on the key is what stops two
+	// instances from both creating the requests.
+	if raw := strings.TrimSpace(c.Get(headerIdempotencyKey)); raw != "" {
+		key, ok := parseIdempotencyKey(raw)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Idempotency-Key must be a UUID",
+			})
+		}
+
+		if !h.lockIdempotencyKey(key) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "an upload with this Idempotency-Key is still in progress",
+			})
+		}
+		defer h.unlockIdempotencyKey(key)
+
+		params.idempotencyKey = key
+		params.fingerprint, err = uploadFingerprint(mode, params, files)
+		if err != nil {
+			h.logger.Error("failed to fingerprint upload", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to read uploaded files",
+			})
+		}
+
+		// A key older than the window may be used again, so it no longer holds its unique slot
+		since := time.Now().Add(-h.cfg.Storage.IdempotencyWindow)
+		if err := h.requestRepo.ReleaseIdempotencyKey(c.Context(), key, since); err != nil {
+			h.logger.Error("failed to release idempotency key", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to check Idempotency-Key",
+			})
+		}
+
+		if replayed, err := h.replay(c, params, since); replayed || err != nil {
+			return err
+		}
+	}
+
+	// Validate every file, collecting rejections instead of failing the whole upload
+	fileErrors := []fileError{}
+	var images, archives []*multipart.FileHeader
+	for _, file := range files {
+		if file.Size > h.cfg.Storage.MaxUploadSize {
+			fileErrors = append(fileErrors, fileError{Filename: file.Filename, Error: "file too large"})
+			continue
+		}
+
+		fileType, ok := h.getFileType(file.Filename)
+		switch {
+		case !ok:
+			fileErrors = append(fileErrors, fileError{Filename: file.Filename, Error: "unsupported file type"})
+		case fileType.IsArchive():
+			archives = append(archives, file)
+		default:
+			images = append(images, file)
+		}
+	}
+
+	// Group files into requests: archives are always translated on their own,
+	// images are merged into one request unless separate mode is selected
+	var groups [][]*multipart.FileHeader
+	if mode == uploadModeCombined && len(images) > 0 {
+		groups = append(groups, images)
+	} else {
+		for _, image := range images {
+			groups = append(groups, []*multipart.FileHeader{image})
+		}
+	}
+	for _, archive := range archives {
+		groups = append(groups, []*multipart.FileHeader{archive})
+	}
+
+	requests := make([]*domain.Request, 0, len(groups))
+	for seq, group := range groups {
+		request, err := h.createRequest(c, group, params, seq)
+		if errors.Is(err, domain.ErrIdempotencyKeyTaken) {
+			// A concurrent upload with the same key won the race, answer with its requests
+			since := time.Now().Add(-h.cfg.Storage.IdempotencyWindow)
+			if replayed, err := h.replay(c, params, since); replayed || err != nil {
+				return err
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "an upload with this Idempotency-Key is still in progress",
+			})
+		}
+		if err != nil {
+			h.logger.Error("failed to create request",
+				zap.String("filename", group[0].Filename),
+				zap.Error(err),
+			)
+			message := "failed to save file"
+			var appErr *domain.AppError
+			if errors.As(err, &appErr) {
+				message = appErr.Message
+			}
+			for _, file := range group {
+				fileErrors = append(fileErrors, fileError{Filename: file.Filename, Error: message})
+			}
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	if len(requests) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "unsupported file type",
+			"error":  "no valid files provided",
+			"errors": fileErrors,
 		})
 	}
 
-	// Create request
-	request := domain.NewRequest(filename, domain.FileType(fileType))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"requests": requests,
+		"errors":   fileErrors,
+	})
+}
 
-	// Create upload directory
-	uploadDir := filepath.Join(h.cfg.Storage.Path, "uploads", request.ID.String())
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		h.logger.Error("failed to create upload directory", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file",
+// replay answers an upload with the requests created since the given time under its
+// Idempotency-Key. It reports false when there are none and the upload should go ahead.
+func (h *UploadHandler) replay(c *fiber.Ctx, params uploadParams, since time.Time) (bool, error) {
+	existing, err := h.requestRepo.FindByIdempotencyKey(c.Context(), params.idempotencyKey, since)
+	if err != nil {
+		h.logger.Error("failed to look up idempotency key", zap.Error(err))
+		return true, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check Idempotency-Key",
 		})
 	}
+	if len(existing) == 0 {
+		return false, nil
+	}
 
-	// Save file
-	filePath := filepath.Join(uploadDir, filename)
-	if err := c.SaveFile(file, filePath); err != nil {
-		h.logger.Error("failed to save file", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file",
+	if existing[0].IdempotencyHash == nil || *existing[0].IdempotencyHash != params.fingerprint {
+		return true, c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "Idempotency-Key was already used for a different upload",
+			"code":  domain.CodeIdempotencyKeyReused,
 		})
 	}
 
-	// For ZIP files, count pages immediately
-	if fileType == "zip" {
-		pageCount, err := h.countImagesInZip(filePath)
-		if err != nil {
-			h.logger.Warn("failed to count images in zip", zap.Error(err))
-		} else {
-			request.PageCount = pageCount
-			h.logger.Info("counted pages in zip", zap.Int("pages", pageCount))
-		}
+	h.logger.Info("replayed idempotent upload",
+		zap.String("idempotency_key", params.idempotencyKey),
+		zap.Int("requests", len(existing)),
+	)
+	c.Set(headerIdempotentReplayed, "true")
+	return true, c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"requests": existing,
+		"errors":   []fileError{},
+	})
+}
+
+// createRequest stores a group of uploaded files as one translation request and enqueues it.
+// A group holds either a single file or several images that are packed into one archive;
+// seq is the position of the group in the upload.
+func (h *UploadHandler) createRequest(
+	c *fiber.Ctx,
+	files []*multipart.FileHeader,
+	params uploadParams,
+	seq int,
+) (*domain.Request, error) {
+	filename := filepath.Base(files[0].Filename)
+	fileType, _ := h.getFileType(filename)
+	if len(files) > 1 {
+		filename = fmt.Sprintf("%s (+%d more)", filename, len(files)-1)
+		fileType = domain.FileTypeZip
 	}
 
-	// Save request to database
-	if err := h.requestRepo.Create(c.Context(), request); err != nil {
-		h.logger.Error("failed to create request", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create request",
-		})
+	// Create request
+	request := domain.NewRequest(filename, fileType)
+	params.apply(request, seq)
+
+	// Create upload directory
+	uploadDir := filepath.Join(h.cfg.Storage.Path, "uploads", request.ID.String())
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
-	// Enqueue translation job
-	if h.queueClient != nil {
-		if err := h.queueClient.EnqueueTranslation(c.Context(), request.ID, filePath, fileType); err != nil {
-			h.logger.Error("failed to enqueue job", zap.Error(err))
-			// Continue anyway - job can be retried
+	// Save file(s)
+	var filePath string
+	if len(files) > 1 {
+		filePath = filepath.Join(uploadDir, batchArchiveName)
+		if err := packImages(files, filePath); err != nil {
+			os.RemoveAll(uploadDir)
+			return nil, fmt.Errorf("failed to pack images: %w", err)
+		}
+		request.PageCount = len(files)
+	} else {
+		savedPath := filepath.Join(uploadDir, filepath.Base(files[0].Filename))
+		if err := c.SaveFile(files[0], savedPath); err != nil {
+			os.RemoveAll(uploadDir)
+			return nil, fmt.Errorf("failed to save file: %w", err)
+		}
+
+		var err error
+		filePath, err = h.preparePages(c.Context(), request, savedPath)
+		if err != nil {
+			os.RemoveAll(uploadDir)
+			return nil, err
 		}
 	}
 
+	if err := h.submit(c.Context(), request, filePath); err != nil {
+		os.RemoveAll(uploadDir)
+		return nil, err
+	}
+
 	h.logger.Info("file uploaded",
 		zap.String("requestId", request.ID.String()),
 		zap.String("filename", filename),
-		zap.Int64("size", file.Size),
+		zap.Int("files", len(files)),
+		zap.Int("pages", request.PageCount),
 	)
 
-	return c.Status(fiber.StatusCreated).JSON(request)
+	return request, nil
 }
 
-func (h *UploadHandler) getFileType(filename string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
+// preparePages counts the pages of a stored upload and normalises archives the worker
+// cannot read directly. It returns the path of the file to translate.
+func (h *UploadHandler) preparePages(ctx context.Context, request *domain.Request, filePath string) (string, error) {
+	switch {
+	case request.FileType == domain.FileTypeZip:
+		// For ZIP files, validate the archive and count pages immediately
+		pageCount, err := h.countImagesInZip(filePath)
+		if err != nil {
+			h.logger.Warn("rejected zip archive", zap.Error(err))
+			return "", err
+		}
+		request.PageCount = pageCount
+		h.logger.Info("counted pages in zip", zap.Int("pages", pageCount))
+		return filePath, nil
 
-	imageExts := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
+	case request.FileType.NeedsIngestion():
+		pageSet, err := h.ingester.Ingest(ctx, filePath, request.FileType, filepath.Dir(filePath))
+		if err != nil {
+			return "", err
+		}
+		request.PageCount = len(pageSet.Pages)
+		return pageSet.ArchivePath, nil
+
+	default:
+		request.PageCount = 1
+		return filePath, nil
+	}
+}
+
+// submit saves a new request to the database together with the outbox task that enqueues
+// its translation job, so the job is enqueued even if the queue is down right now
+func (h *UploadHandler) submit(ctx context.Context, request *domain.Request, filePath string) error {
+	if err := h.requestRepo.CreateWithTask(ctx, request, domain.NewOutboxTask(request, filePath)); err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if imageExts[ext] {
-		return "image"
+	// Enqueue right away instead of waiting for the next poll
+	if h.dispatcher != nil {
+		h.dispatcher.Wake()
 	}
 
-	if ext == ".zip" {
-		return "zip"
+	return nil
+}
+
+// parseTranslationOptions reads the optional translation settings of an upload.
+// get returns the raw value of a form field or tus metadata key.
+func parseTranslationOptions(get func(key string) string) (domain.TranslationOptions, error) {
+	options := domain.TranslationOptions{
+		SourceLang: strings.ToLower(strings.TrimSpace(get("sourceLang"))),
+		TargetLang: strings.ToLower(strings.TrimSpace(get("targetLang"))),
+		Font:       strings.TrimSpace(get("font")),
+	}
+
+	if value := strings.TrimSpace(get("detectionConfidence")); value != "" {
+		confidence, err := strconv.ParseFloat(value, 64)
+		if err != nil || confidence <= 0 {
+			return options, domain.NewAppError(domain.CodeInvalidOptions,
+				"detection confidence must be between 0 and 1", domain.ErrInvalidInput)
+		}
+		options.DetectionConfidence = confidence
+	}
+
+	if value := strings.TrimSpace(get("outputQuality")); value != "" {
+		quality, err := strconv.Atoi(value)
+		if err != nil || quality <= 0 {
+			return options, domain.NewAppError(domain.CodeInvalidOptions,
+				"output quality must be between 1 and 100", domain.ErrInvalidInput)
+		}
+		options.OutputQuality = quality
 	}
 
-	return ""
+	return options, options.Validate()
 }
 
-// countImagesInZip counts the number of image files in a ZIP archive
+// optionsError builds the response body for invalid translation options or priority
+func optionsError(err error) fiber.Map {
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) {
+		return fiber.Map{"error": appErr.Message, "code": appErr.Code}
+	}
+	return fiber.Map{"error": "invalid translation options"}
+}
+
+func (h *UploadHandler) getFileType(filename string) (domain.FileType, bool) {
+	return domain.FileTypeFromExt(strings.ToLower(filepath.Ext(filename)))
+}
+
+// packImages writes uploaded images into a single ZIP archive.
+// Entries are prefixed with their upload position so pages keep the order they were sent in.
+func packImages(files []*multipart.FileHeader, destPath string) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+	for i, file := range files {
+		name := fmt.Sprintf("%03d_%s", i+1, filepath.Base(file.Filename))
+		// Images are already compressed, store them as-is
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// countImagesInZip validates a ZIP archive against the extraction limits and
+// counts the image files it contains, without decompressing them
 func (h *UploadHandler) countImagesInZip(zipPath string) (int, error) {
-	reader, err := zip.OpenReader(zipPath)
+	names, err := archive.List(zipPath, archive.NewLimits(&h.cfg.Archive))
 	if err != nil {
 		return 0, err
 	}
-	defer reader.Close()
 
 	validExts := map[string]bool{
 		".jpg":  true,
@@ -171,12 +490,10 @@ func (h *UploadHandler) countImagesInZip(zipPath string) (int, error) {
 	}
 
 	count := 0
-	for _, file := range reader.File {
-		if !file.FileInfo().IsDir() {
-			ext := strings.ToLower(filepath.Ext(file.Name))
-			if validExts[ext] {
-				count++
-			}
+	for _, name := range names {
+		ext := strings.ToLower(filepath.Ext(name))
+		if validExts[ext] {
+			count++
 		}
 	}
 
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminAuth protects admin routes with a bearer token.
+// An empty token leaves them open, as for every other route in development.
+func AdminAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Next()
+		}
+
+		provided, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "invalid admin token",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -9,8 +9,9 @@ import (
 func CORS(origins []string) fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     joinOrigins(origins),
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowMethods:     "GET,POST,PUT,PATCH,HEAD,DELETE,OPTIONS",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Tus-Resumable,Upload-Length,Upload-Metadata,Upload-Offset,Idempotency-Key",
+		ExposeHeaders:    "Location,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,Upload-Offset,Upload-Length,Upload-Request-Id,Idempotent-Replayed",
 		AllowCredentials: true,
 	})
 }
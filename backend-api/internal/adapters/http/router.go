@@ -3,6 +3,7 @@ package http
 import (
 	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/http/handlers"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/http/middleware"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/application"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/gofiber/fiber/v2"
@@ -16,7 +17,11 @@ func SetupRoutes(
 	logger *zap.Logger,
 	requestRepo ports.RequestRepository,
 	resultRepo ports.ResultRepository,
+	pageRepo ports.PageRepository,
 	queueClient ports.QueueClient,
+	dispatcher *application.OutboxDispatcher,
+	ingester ports.Ingester,
+	publisher ports.ProgressPublisher,
 ) {
 	// Middleware
 	app.Use(middleware.Recovery())
@@ -34,21 +39,42 @@ func SetupRoutes(
 	api := app.Group("/api")
 
 	// Upload handler
-	uploadHandler := handlers.NewUploadHandler(requestRepo, queueClient, cfg, logger)
+	uploadHandler := handlers.NewUploadHandler(requestRepo, dispatcher, ingester, cfg, logger)
 	api.Post("/translate", uploadHandler.Upload)
 
+	// Resumable uploads (tus protocol)
+	api.Options("/uploads", uploadHandler.TusOptions)
+	api.Post("/uploads", uploadHandler.TusCreate)
+	api.Head("/uploads/:id", uploadHandler.TusHead)
+	api.Patch("/uploads/:id", uploadHandler.TusPatch)
+	api.Delete("/uploads/:id", uploadHandler.TusDelete)
+
 	// Requests handler
-	requestsHandler := handlers.NewRequestsHandler(requestRepo, logger)
+	requestService := application.NewRequestService(requestRepo, resultRepo, queueClient, dispatcher, publisher, cfg, logger)
+	requestsHandler := handlers.NewRequestsHandler(requestRepo, requestService, logger)
 	api.Get("/requests", requestsHandler.List)
+	api.Delete("/requests", requestsHandler.BulkDelete)
 	api.Get("/requests/:id", requestsHandler.GetByID)
+	api.Delete("/requests/:id", requestsHandler.Delete)
+	api.Post("/requests/:id/cancel", requestsHandler.Cancel)
+	api.Post("/requests/:id/retry", requestsHandler.Retry)
 
 	// Events handler (SSE)
-	eventsHandler := handlers.NewEventsHandler(requestRepo, cfg, logger)
+	eventsHandler := handlers.NewEventsHandler(requestRepo, queueClient, cfg, logger)
 	api.Get("/requests/:id/events", eventsHandler.StreamProgress)
 
 	// Results handler
-	resultsHandler := handlers.NewResultsHandler(requestRepo, resultRepo, logger)
+	resultsHandler := handlers.NewResultsHandler(requestRepo, resultRepo, pageRepo, requestService, logger)
 	api.Get("/results/:id", resultsHandler.GetByRequestID)
+	api.Post("/results/:id/pages/:page/retranslate", resultsHandler.RetranslatePage)
+
+	// Admin endpoints
+	reconcileService := application.NewReconcileService(requestRepo, queueClient, requestService, cfg, logger)
+	adminHandler := handlers.NewAdminHandler(requestService, reconcileService, logger)
+	admin := api.Group("/admin", middleware.AdminAuth(cfg.Admin.Token))
+	admin.Put("/requests/:id/priority", adminHandler.SetPriority)
+	admin.Get("/reconcile", adminHandler.InspectReconcile)
+	admin.Post("/reconcile", adminHandler.Reconcile)
 
 	// File serving
 	filesHandler := handlers.NewFilesHandler(cfg, logger)
@@ -0,0 +1,36 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/pkg/natsort"
+)
+
+// extractArchivePages stages the images of a ZIP/CBZ, RAR/CBR or 7z/CB7 archive in natural order.
+// Entries are read in archive order, which RAR and solid 7z archives require, and sorted after staging.
+func extractArchivePages(ctx context.Context, srcPath string, format archive.Format, limits archive.Limits, stageDir string) ([]page, error) {
+	var pages []page
+	err := archive.Walk(ctx, srcPath, format, limits, func(name string, r io.Reader) error {
+		if !isImage(name) {
+			return nil
+		}
+
+		p, err := stagePage(stageDir, len(pages), name, r)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return natsort.Less(pages[i].name, pages[j].name)
+	})
+	return pages, nil
+}
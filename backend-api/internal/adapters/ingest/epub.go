@@ -0,0 +1,199 @@
+package ingest
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
+)
+
+// epubContainer is META-INF/container.xml, which points to the package document
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the subset of the OPF package document needed to order pages
+type epubPackage struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// extractEPUBPages stages the images of a fixed-layout (comic) EPUB in reading order.
+// The spine gives the order of content documents; every image they reference becomes a page.
+// EPUBs whose spine references no images fall back to the manifest order of image items.
+func extractEPUBPages(ctx context.Context, srcPath string, limits archive.Limits, stageDir string) ([]page, error) {
+	files, err := archive.OpenZip(srcPath, limits)
+	if err != nil {
+		return nil, invalidArchive("epub", err)
+	}
+	defer files.Close()
+
+	var container epubContainer
+	if err := decodeXMLEntry(files, "META-INF/container.xml", &container); err != nil {
+		return nil, invalidArchive("epub", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, invalidArchive("epub", errors.New("container.xml has no rootfile"))
+	}
+
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeXMLEntry(files, opfPath, &pkg); err != nil {
+		return nil, invalidArchive("epub", err)
+	}
+	opfDir := path.Dir(opfPath)
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = resolveHref(opfDir, item.Href)
+	}
+
+	// Collect image references from the spine documents, in order
+	var imagePaths []string
+	seen := map[string]bool{}
+	for _, itemRef := range pkg.Spine {
+		docPath, ok := hrefs[itemRef.IDRef]
+		if !ok {
+			continue
+		}
+		refs, err := documentImages(files, docPath)
+		if err != nil {
+			return nil, invalidArchive("epub", err)
+		}
+		for _, ref := range refs {
+			if !seen[ref] && isImage(ref) {
+				seen[ref] = true
+				imagePaths = append(imagePaths, ref)
+			}
+		}
+	}
+
+	if len(imagePaths) == 0 {
+		for _, item := range pkg.Manifest {
+			href := resolveHref(opfDir, item.Href)
+			if strings.HasPrefix(item.MediaType, "image/") && isImage(href) {
+				imagePaths = append(imagePaths, href)
+			}
+		}
+	}
+
+	pages := make([]page, 0, len(imagePaths))
+	for idx, imagePath := range imagePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file, ok := files.Files[imagePath]
+		if !ok {
+			continue
+		}
+		rc, err := files.Open(file)
+		if err != nil {
+			return nil, invalidArchive("epub", err)
+		}
+		p, err := stagePage(stageDir, idx, imagePath, rc)
+		rc.Close()
+		if err != nil {
+			return nil, invalidArchive("epub", err)
+		}
+		pages = append(pages, p)
+	}
+
+	return pages, nil
+}
+
+// documentImages returns the images referenced by an XHTML/SVG content document
+func documentImages(files *archive.Zip, docPath string) ([]string, error) {
+	file, ok := files.Files[docPath]
+	if !ok {
+		return nil, nil
+	}
+
+	rc, err := files.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	docDir := path.Dir(docPath)
+	var refs []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		// <img src="..."> in XHTML, <image xlink:href="..."> in SVG wrappers
+		var attr string
+		switch strings.ToLower(start.Name.Local) {
+		case "img":
+			attr = "src"
+		case "image":
+			attr = "href"
+		default:
+			continue
+		}
+		for _, a := range start.Attr {
+			if strings.ToLower(a.Name.Local) == attr && a.Value != "" {
+				refs = append(refs, resolveHref(docDir, a.Value))
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+// decodeXMLEntry decodes an XML file from the archive
+func decodeXMLEntry(files *archive.Zip, name string, v interface{}) error {
+	file, ok := files.Files[name]
+	if !ok {
+		return errors.New("missing " + name)
+	}
+
+	rc, err := files.Open(file)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	return decoder.Decode(v)
+}
+
+// resolveHref resolves a relative, URL-encoded reference against the directory of its document
+func resolveHref(baseDir, href string) string {
+	if i := strings.IndexAny(href, "#?"); i >= 0 {
+		href = href[:i]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return strings.TrimPrefix(path.Clean(path.Join(baseDir, href)), "/")
+}
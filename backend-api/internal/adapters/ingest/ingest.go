@@ -0,0 +1,187 @@
+package ingest
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	// PageArchiveName is the normalised archive written next to the upload
+	PageArchiveName = "pages.zip"
+
+	// stagingDirName holds extracted pages until they are packed
+	stagingDirName = ".ingest"
+)
+
+// imageExts lists the page formats the worker can translate
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".bmp":  true,
+}
+
+// page is a single extracted page waiting to be packed
+type page struct {
+	name string // name of the page inside the source document
+	path string // staged copy on disk
+}
+
+type ingester struct {
+	limits archive.Limits
+	logger *zap.Logger
+}
+
+// NewIngester creates an ingester for CBZ, CBR/RAR, 7z, PDF and EPUB uploads.
+// Archives are read within the configured extraction limits.
+func NewIngester(cfg *config.ArchiveConfig, logger *zap.Logger) ports.Ingester {
+	return &ingester{
+		limits: archive.NewLimits(cfg),
+		logger: logger,
+	}
+}
+
+func (i *ingester) Ingest(
+	ctx context.Context,
+	srcPath string,
+	fileType domain.FileType,
+	destDir string,
+) (*ports.PageSet, error) {
+	stageDir := filepath.Join(destDir, stagingDirName)
+	if err := os.MkdirAll(stageDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	var pages []page
+	var err error
+	switch fileType {
+	case domain.FileTypeZip, domain.FileTypeCBZ:
+		pages, err = extractArchivePages(ctx, srcPath, archive.FormatZip, i.limits, stageDir)
+	case domain.FileTypeRAR:
+		pages, err = extractArchivePages(ctx, srcPath, archive.FormatRAR, i.limits, stageDir)
+	case domain.FileType7z:
+		pages, err = extractArchivePages(ctx, srcPath, archive.Format7z, i.limits, stageDir)
+	case domain.FileTypePDF:
+		pages, err = extractPDFPages(ctx, srcPath, stageDir)
+	case domain.FileTypeEPUB:
+		pages, err = extractEPUBPages(ctx, srcPath, i.limits, stageDir)
+	default:
+		return nil, domain.NewAppError(domain.CodeUnsupportedFormat,
+			fmt.Sprintf("cannot ingest %s files", fileType), domain.ErrInvalidFileType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pages) == 0 {
+		return nil, domain.NewAppError(domain.CodeNoPages, "no images found in upload", domain.ErrInvalidArchive)
+	}
+
+	archivePath := filepath.Join(destDir, PageArchiveName)
+	names, err := packPages(pages, archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write page archive: %w", err)
+	}
+
+	i.logger.Info("ingested upload",
+		zap.String("source", srcPath),
+		zap.String("file_type", string(fileType)),
+		zap.Int("pages", len(names)),
+	)
+
+	return &ports.PageSet{
+		ArchivePath: archivePath,
+		Pages:       names,
+	}, nil
+}
+
+// packPages writes pages into a ZIP in the given order.
+// Entries are flattened and prefixed with their position so any consumer that sorts names keeps reading order.
+func packPages(pages []page, destPath string) ([]string, error) {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+	names := make([]string, 0, len(pages))
+	for idx, p := range pages {
+		name := fmt.Sprintf("%04d_%s", idx+1, path.Base(p.name))
+		// Pages are already compressed images, store them as-is
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return nil, err
+		}
+
+		src, err := os.Open(p.path)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(entry, src)
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return names, out.Close()
+}
+
+// stagePage copies a page from r into the staging directory
+func stagePage(stageDir string, index int, name string, r io.Reader) (page, error) {
+	stagedPath := filepath.Join(stageDir, fmt.Sprintf("%06d%s", index, strings.ToLower(path.Ext(name))))
+	out, err := os.Create(stagedPath)
+	if err != nil {
+		return page{}, err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return page{}, err
+	}
+	if err := out.Close(); err != nil {
+		return page{}, err
+	}
+
+	return page{name: name, path: stagedPath}, nil
+}
+
+// isImage reports whether an archive entry is a page the worker can translate.
+// Hidden files and macOS resource forks are ignored.
+func isImage(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+		return false
+	}
+	return imageExts[strings.ToLower(path.Ext(name))]
+}
+
+// invalidArchive wraps a read failure so it surfaces as a client error.
+// Errors that already carry a code, such as extraction limit rejections, are kept as-is.
+func invalidArchive(fileType string, err error) error {
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return domain.NewAppError(domain.CodeInvalidArchive, "failed to read "+fileType, fmt.Errorf("%w: %v", domain.ErrInvalidArchive, err))
+}
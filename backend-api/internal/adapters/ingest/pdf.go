@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func init() {
+	// pdfcpu would otherwise create a configuration directory in the user's home
+	api.DisableConfigDir()
+}
+
+// extractPDFPages stages the scanned image of every page of an image-only PDF.
+// When a page embeds several images the largest one is taken as the page scan;
+// pages without a usable image (text-only pages, unsupported encodings) are skipped.
+func extractPDFPages(ctx context.Context, srcPath, stageDir string) ([]page, error) {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTIMAGES
+
+	pdfCtx, err := api.ReadValidateAndOptimize(file, conf)
+	if err != nil {
+		return nil, invalidArchive("pdf document", err)
+	}
+
+	var pages []page
+	for pageNr := 1; pageNr <= pdfCtx.PageCount; pageNr++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		images, err := pdfcpu.ExtractPageImages(pdfCtx, pageNr, false)
+		if err != nil {
+			return nil, invalidArchive("pdf document", err)
+		}
+
+		var best *model.Image
+		for _, img := range images {
+			if img.Thumb || !isImage("page."+img.FileType) {
+				continue
+			}
+			if best == nil || img.Width*img.Height > best.Width*best.Height {
+				img := img
+				best = &img
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		name := fmt.Sprintf("page_%04d.%s", pageNr, best.FileType)
+		p, err := stagePage(stageDir, pageNr, name, best)
+		if err != nil {
+			return nil, invalidArchive("pdf document", err)
+		}
+		pages = append(pages, p)
+	}
+
+	return pages, nil
+}
@@ -3,8 +3,11 @@ package asynq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
@@ -14,27 +17,64 @@ import (
 
 const (
 	// Task types
-	TaskTypeTranslation = "translation:process"
+	TaskTypeTranslation     = "translation:process"
+	TaskTypePageTranslation = "translation:page"
+	TaskTypeCleanup         = "storage:cleanup"
+	TaskTypeReconcile       = "requests:reconcile"
 
 	// Queue names
 	QueueCritical = "critical"
 	QueueDefault  = "default"
+	QueueLow      = "low"
+
+	// taskRetention keeps completed tasks visible to the reconciler, which would otherwise
+	// take a finished task for a lost one
+	taskRetention = 24 * time.Hour
+
+	// taskTimeoutMargin is added to the job deadline for the handler to prepare the job and
+	// save its results, so the watchdog stops a job before asynq does
+	taskTimeoutMargin = 5 * time.Minute
 )
 
+// queues lists every queue a translation task can be in
+var queues = []string{QueueCritical, QueueDefault, QueueLow}
+
+// queueFor returns the queue serving a request priority
+func queueFor(priority domain.Priority) string {
+	switch priority {
+	case domain.PriorityUrgent:
+		return QueueCritical
+	case domain.PriorityBulk:
+		return QueueLow
+	default:
+		return QueueDefault
+	}
+}
+
 // TranslationPayload represents the payload for a translation task
 type TranslationPayload struct {
-	RequestID uuid.UUID `json:"requestId"`
-	FilePath  string    `json:"filePath"`
-	FileType  string    `json:"fileType"`
+	RequestID uuid.UUID                 `json:"requestId"`
+	FilePath  string                    `json:"filePath"`
+	FileType  string                    `json:"fileType"`
+	Options   domain.TranslationOptions `json:"options"`
+}
+
+// PageTranslationPayload represents the payload for re-translating one page of a request
+type PageTranslationPayload struct {
+	RequestID  uuid.UUID                 `json:"requestId"`
+	PageNumber int                       `json:"pageNumber"`
+	Options    domain.TranslationOptions `json:"options"`
 }
 
 type queueClient struct {
-	client *asynq.Client
-	logger *zap.Logger
+	client    *asynq.Client
+	inspector *asynq.Inspector
+	jobLimits jobLimits
+	logger    *zap.Logger
 }
 
 // NewQueueClient creates a new Asynq queue client
-func NewQueueClient(cfg *config.RedisConfig, logger *zap.Logger) (ports.QueueClient, error) {
+func NewQueueClient(cfg *config.RedisConfig, workerCfg *config.WorkerConfig, logger *zap.Logger) (ports.QueueClient, error) {
 	redisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.Addr,
 		Password: cfg.Password,
@@ -42,22 +82,44 @@ func NewQueueClient(cfg *config.RedisConfig, logger *zap.Logger) (ports.QueueCli
 	}
 
 	client := asynq.NewClient(redisOpt)
+	inspector := asynq.NewInspector(redisOpt)
 
 	logger.Info("asynq client initialized",
 		zap.String("redis_addr", cfg.Addr),
 	)
 
 	return &queueClient{
-		client: client,
-		logger: logger,
+		client:    client,
+		inspector: inspector,
+		jobLimits: newJobLimits(workerCfg),
+		logger:    logger,
 	}, nil
 }
 
-func (q *queueClient) EnqueueTranslation(ctx context.Context, requestID uuid.UUID, filePath string, fileType string) error {
+// taskOptions are the options of a translation task over pages pages. Its timeout is the
+// job deadline plus taskTimeoutMargin; without a job deadline asynq's default of 30 minutes applies.
+func (q *queueClient) taskOptions(queue string, maxRetry int, pages int) []asynq.Option {
+	opts := []asynq.Option{asynq.Queue(queue), asynq.MaxRetry(maxRetry)}
+	if deadline := q.jobLimits.deadline(pages); deadline > 0 {
+		opts = append(opts, asynq.Timeout(deadline+taskTimeoutMargin))
+	}
+	return opts
+}
+
+func (q *queueClient) EnqueueTranslation(
+	ctx context.Context,
+	requestID uuid.UUID,
+	filePath string,
+	fileType string,
+	pages int,
+	options domain.TranslationOptions,
+	priority domain.Priority,
+) error {
 	payload := TranslationPayload{
 		RequestID: requestID,
 		FilePath:  filePath,
 		FileType:  fileType,
+		Options:   options,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -65,14 +127,21 @@ func (q *queueClient) EnqueueTranslation(ctx context.Context, requestID uuid.UUI
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	task := asynq.NewTask(TaskTypeTranslation, payloadBytes)
+	// The task ID is the request ID so the task can be found again to cancel it
+	task := asynq.NewTask(TaskTypeTranslation, payloadBytes, asynq.TaskID(requestID.String()))
 
-	// Enqueue task with options
-	info, err := q.client.EnqueueContext(ctx, task,
-		asynq.Queue(QueueDefault),
-		asynq.MaxRetry(3),
-		asynq.Timeout(0), // No timeout, let worker config handle it
-	)
+	opts := append(q.taskOptions(queueFor(priority), 3, pages), asynq.Retention(taskRetention))
+	info, err := q.client.EnqueueContext(ctx, task, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		// A previous run of the same request left an archived task behind
+		if err := q.deleteFinishedTask(requestID.String()); err != nil {
+			if errors.Is(err, asynq.ErrTaskIDConflict) {
+				return fmt.Errorf("request is already enqueued: %w", domain.ErrInvalidState)
+			}
+			return fmt.Errorf("failed to enqueue task: %w", err)
+		}
+		info, err = q.client.EnqueueContext(ctx, task, opts...)
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to enqueue task: %w", err)
@@ -82,11 +151,243 @@ func (q *queueClient) EnqueueTranslation(ctx context.Context, requestID uuid.UUI
 		zap.String("request_id", requestID.String()),
 		zap.String("task_id", info.ID),
 		zap.String("queue", info.Queue),
+		zap.String("priority", string(priority)),
+	)
+
+	return nil
+}
+
+func (q *queueClient) EnqueuePageTranslation(
+	ctx context.Context,
+	requestID uuid.UUID,
+	pageNumber int,
+	options domain.TranslationOptions,
+	priority domain.Priority,
+) error {
+	payload := PageTranslationPayload{
+		RequestID:  requestID,
+		PageNumber: pageNumber,
+		Options:    options,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	// One task per page at a time
+	taskID := pageTaskID(requestID, pageNumber)
+	task := asynq.NewTask(TaskTypePageTranslation, payloadBytes, asynq.TaskID(taskID))
+
+	opts := q.taskOptions(queueFor(priority), 3, 1)
+	info, err := q.client.EnqueueContext(ctx, task, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		if err := q.deleteFinishedTask(taskID); err != nil {
+			if errors.Is(err, asynq.ErrTaskIDConflict) {
+				return fmt.Errorf("page %d is already being re-translated: %w", pageNumber, domain.ErrInvalidState)
+			}
+			return fmt.Errorf("failed to enqueue task: %w", err)
+		}
+		info, err = q.client.EnqueueContext(ctx, task, opts...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	q.logger.Info("page translation task enqueued",
+		zap.String("request_id", requestID.String()),
+		zap.Int("page", pageNumber),
+		zap.String("task_id", info.ID),
+	)
+
+	return nil
+}
+
+// pageTaskID is the task ID of the re-translation of one page
+func pageTaskID(requestID uuid.UUID, pageNumber int) string {
+	return fmt.Sprintf("%s:page:%d", requestID, pageNumber)
+}
+
+func (q *queueClient) HasPageTranslation(ctx context.Context, requestID uuid.UUID, pageCount int) (bool, error) {
+	for page := 1; page <= pageCount; page++ {
+		info, err := q.findTask(pageTaskID(requestID, page))
+		if err != nil {
+			return false, err
+		}
+		if info != nil && info.State != asynq.TaskStateArchived && info.State != asynq.TaskStateCompleted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *queueClient) MoveTranslation(ctx context.Context, requestID uuid.UUID, priority domain.Priority) error {
+	info, err := q.findTask(requestID.String())
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("request has no task: %w", domain.ErrInvalidState)
+	}
+
+	queue := queueFor(priority)
+	if info.Queue == queue {
+		return nil
+	}
+
+	// A task waiting for its next attempt cannot be moved: asynq would reset its retry count
+	switch info.State {
+	case asynq.TaskStatePending, asynq.TaskStateScheduled:
+	default:
+		return fmt.Errorf("task is %s: %w", info.State, domain.ErrInvalidState)
+	}
+
+	// asynq cannot move a task, so it is deleted and enqueued again under the same ID.
+	// Losing the race against a worker leaves the task where it was.
+	if err := q.inspector.DeleteTask(info.Queue, info.ID); err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) {
+			return fmt.Errorf("task was picked up: %w", domain.ErrInvalidState)
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	if err := q.reenqueue(ctx, info, queue); err != nil {
+		// Put the task back rather than leave the request without one
+		if restoreErr := q.reenqueue(context.WithoutCancel(ctx), info, info.Queue); restoreErr != nil {
+			q.logger.Error("failed to restore moved task, the reconciler will requeue it",
+				zap.String("request_id", requestID.String()),
+				zap.String("queue", info.Queue),
+				zap.Error(restoreErr),
+			)
+		}
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	q.logger.Info("translation task moved",
+		zap.String("request_id", requestID.String()),
+		zap.String("from_queue", info.Queue),
+		zap.String("to_queue", queue),
+	)
+
+	return nil
+}
+
+// reenqueue enqueues the task described by info in queue, with its ID, retry limit, timeout and schedule
+func (q *queueClient) reenqueue(ctx context.Context, info *asynq.TaskInfo, queue string) error {
+	task := asynq.NewTask(info.Type, info.Payload, asynq.TaskID(info.ID))
+	opts := []asynq.Option{
+		asynq.Queue(queue),
+		asynq.MaxRetry(info.MaxRetry),
+		asynq.Timeout(info.Timeout),
+		asynq.Retention(info.Retention),
+	}
+	if info.State == asynq.TaskStateScheduled {
+		opts = append(opts, asynq.ProcessAt(info.NextProcessAt))
+	}
+	_, err := q.client.EnqueueContext(ctx, task, opts...)
+	return err
+}
+
+func (q *queueClient) CancelTranslation(ctx context.Context, requestID uuid.UUID) error {
+	info, err := q.findTask(requestID.String())
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+
+	switch info.State {
+	case asynq.TaskStateActive:
+		// Cancels the context of the handler on whichever worker runs the task
+		if err := q.inspector.CancelProcessing(info.ID); err != nil {
+			return fmt.Errorf("failed to cancel task: %w", err)
+		}
+	case asynq.TaskStatePending, asynq.TaskStateScheduled, asynq.TaskStateRetry:
+		if err := q.inspector.DeleteTask(info.Queue, info.ID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+	default:
+		return nil
+	}
+
+	q.logger.Info("translation task cancelled",
+		zap.String("request_id", requestID.String()),
+		zap.String("state", info.State.String()),
 	)
 
 	return nil
 }
 
+func (q *queueClient) DeleteTranslation(ctx context.Context, requestID uuid.UUID) error {
+	info, err := q.findTask(requestID.String())
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+
+	if info.State == asynq.TaskStateActive {
+		// Active tasks cannot be deleted, only cancelled
+		if err := q.inspector.CancelProcessing(info.ID); err != nil {
+			return fmt.Errorf("failed to cancel task: %w", err)
+		}
+	} else if err := q.inspector.DeleteTask(info.Queue, info.ID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	q.logger.Info("translation task deleted",
+		zap.String("request_id", requestID.String()),
+		zap.String("state", info.State.String()),
+	)
+
+	return nil
+}
+
+func (q *queueClient) TranslationTask(ctx context.Context, requestID uuid.UUID) (*domain.TaskInfo, error) {
+	info, err := q.findTask(requestID.String())
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	return &domain.TaskInfo{
+		State:     domain.TaskState(info.State.String()),
+		Queue:     info.Queue,
+		Retried:   info.Retried,
+		MaxRetry:  info.MaxRetry,
+		LastError: info.LastErr,
+	}, nil
+}
+
+// findTask returns the task with the given ID, or nil if it is in no queue
+func (q *queueClient) findTask(taskID string) (*asynq.TaskInfo, error) {
+	for _, queue := range queues {
+		info, err := q.inspector.GetTaskInfo(queue, taskID)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, asynq.ErrTaskNotFound) && !errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// deleteFinishedTask removes an archived or completed task so its ID can be reused
+func (q *queueClient) deleteFinishedTask(taskID string) error {
+	info, err := q.findTask(taskID)
+	if err != nil || info == nil {
+		return err
+	}
+	if info.State != asynq.TaskStateArchived && info.State != asynq.TaskStateCompleted {
+		return fmt.Errorf("request already has a %s task: %w", info.State, asynq.ErrTaskIDConflict)
+	}
+	return q.inspector.DeleteTask(info.Queue, info.ID)
+}
+
 func (q *queueClient) Close() error {
+	q.inspector.Close()
 	return q.client.Close()
 }
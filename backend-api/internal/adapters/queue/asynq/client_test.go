@@ -0,0 +1,42 @@
+package asynq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// taskTimeout is the timeout in opts, 0 if there is none
+func taskTimeout(opts []asynq.Option) time.Duration {
+	for _, opt := range opts {
+		if opt.Type() == asynq.TimeoutOpt {
+			return opt.Value().(time.Duration)
+		}
+	}
+	return 0
+}
+
+func TestTaskOptionsFollowJobDeadline(t *testing.T) {
+	// The default worker limits
+	limits := jobLimits{timeout: 10 * time.Minute, pageTimeout: time.Minute}
+
+	tests := []struct {
+		name   string
+		limits jobLimits
+		pages  int
+		want   time.Duration
+	}{
+		{"one page", limits, 1, 11*time.Minute + taskTimeoutMargin},
+		{"long chapter", limits, 100, 110*time.Minute + taskTimeoutMargin},
+		{"unknown page count", limits, 0, 10*time.Minute + taskTimeoutMargin},
+		{"no job deadline", jobLimits{pageTimeout: time.Minute}, 100, 0},
+	}
+
+	for _, tt := range tests {
+		q := &queueClient{jobLimits: tt.limits}
+		if got := taskTimeout(q.taskOptions(QueueDefault, 3, tt.pages)); got != tt.want {
+			t.Errorf("%s: got timeout %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
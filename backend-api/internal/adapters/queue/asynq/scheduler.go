@@ -0,0 +1,76 @@
+package asynq
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// periodicUniqueTTL keeps several worker processes from enqueuing the same periodic run
+const periodicUniqueTTL = 10 * time.Minute
+
+type scheduler struct {
+	scheduler *asynq.Scheduler
+	schedules map[string]string // cron spec of each periodic task type, empty when disabled
+	logger    *zap.Logger
+}
+
+// NewScheduler creates a scheduler that periodically enqueues the storage cleanup task
+// according to the retention schedule and the reconcile task according to its own schedule
+func NewScheduler(cfg *config.Config, logger *zap.Logger) ports.QueueServer {
+	redisOpt := asynq.RedisClientOpt{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	}
+
+	return &scheduler{
+		scheduler: asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+			Logger: &asynqLogger{logger: logger},
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				if err != nil {
+					logger.Warn("failed to enqueue scheduled task", zap.Error(err))
+				}
+			},
+		}),
+		schedules: map[string]string{
+			TaskTypeCleanup:   cfg.Retention.Schedule,
+			TaskTypeReconcile: cfg.Reconcile.Schedule,
+		},
+		logger: logger,
+	}
+}
+
+func (s *scheduler) Start() error {
+	for taskType, cronspec := range s.schedules {
+		if cronspec == "" {
+			continue
+		}
+
+		// A run that fails is simply repeated on the next tick
+		_, err := s.scheduler.Register(cronspec, asynq.NewTask(taskType, nil),
+			asynq.Queue(QueueDefault),
+			asynq.MaxRetry(0),
+			asynq.Unique(periodicUniqueTTL),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register %s task: %w", taskType, err)
+		}
+	}
+
+	s.logger.Info("starting asynq scheduler",
+		zap.String("cleanup_schedule", s.schedules[TaskTypeCleanup]),
+		zap.String("reconcile_schedule", s.schedules[TaskTypeReconcile]),
+	)
+	return s.scheduler.Start()
+}
+
+func (s *scheduler) Stop() error {
+	s.logger.Info("stopping asynq scheduler")
+	s.scheduler.Shutdown()
+	return nil
+}
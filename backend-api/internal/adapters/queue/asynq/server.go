@@ -1,34 +1,48 @@
 package asynq
 
 import (
-	"archive/zip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/pubsub"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/pkg/natsort"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
 )
 
+const (
+	// retryBaseDelay is the delay before the second attempt of a task
+	retryBaseDelay = 30 * time.Second
+	// retryMaxDelay caps the delay between attempts
+	retryMaxDelay = 10 * time.Minute
+)
+
 type queueServer struct {
 	server      *asynq.Server
 	mux         *asynq.ServeMux
 	logger      *zap.Logger
 	requestRepo ports.RequestRepository
 	resultRepo  ports.ResultRepository
+	pageRepo    ports.PageRepository
 	executor    ports.WorkerExecutor
+	cleaner     ports.StorageCleaner
+	reconciler  ports.Reconciler
 	storagePath string
-	publisher   *pubsub.Publisher
+	limits      archive.Limits
+	jobLimits   jobLimits
+	publisher   ports.ProgressPublisher
 }
 
 // NewQueueServer creates a new Asynq queue server
@@ -37,7 +51,10 @@ func NewQueueServer(
 	logger *zap.Logger,
 	requestRepo ports.RequestRepository,
 	resultRepo ports.ResultRepository,
+	pageRepo ports.PageRepository,
 	executor ports.WorkerExecutor,
+	cleaner ports.StorageCleaner,
+	reconciler ports.Reconciler,
 ) ports.QueueServer {
 	redisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.Redis.Addr,
@@ -52,8 +69,10 @@ func NewQueueServer(
 			Queues: map[string]int{
 				QueueCritical: 6,
 				QueueDefault:  3,
+				QueueLow:      1,
 			},
-			Logger: &asynqLogger{logger: logger},
+			Logger:         &asynqLogger{logger: logger},
+			RetryDelayFunc: retryDelay,
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 				logger.Error("task execution failed",
 					zap.String("type", task.Type()),
@@ -75,13 +94,25 @@ func NewQueueServer(
 		logger:      logger,
 		requestRepo: requestRepo,
 		resultRepo:  resultRepo,
+		pageRepo:    pageRepo,
 		executor:    executor,
+		cleaner:     cleaner,
+		reconciler:  reconciler,
 		storagePath: cfg.Storage.Path,
+		limits:      archive.NewLimits(&cfg.Archive),
+		jobLimits:   newJobLimits(&cfg.Worker),
 		publisher:   publisher,
 	}
 
 	// Register task handlers
 	qs.mux.HandleFunc(TaskTypeTranslation, qs.handleTranslationTask)
+	qs.mux.HandleFunc(TaskTypePageTranslation, qs.handlePageTranslationTask)
+	if cleaner != nil {
+		qs.mux.HandleFunc(TaskTypeCleanup, qs.handleCleanupTask)
+	}
+	if reconciler != nil {
+		qs.mux.HandleFunc(TaskTypeReconcile, qs.handleReconcileTask)
+	}
 
 	logger.Info("asynq server initialized",
 		zap.Int("concurrency", cfg.Worker.Concurrency),
@@ -114,14 +145,27 @@ func (qs *queueServer) handleTranslationTask(ctx context.Context, task *asynq.Ta
 		zap.String("request_id", requestID.String()),
 		zap.String("file_path", payload.FilePath),
 		zap.String("file_type", payload.FileType),
+		zap.Any("options", payload.Options),
 	)
 
-	// Update request status to processing
-	if err := qs.requestRepo.UpdateStatus(ctx, requestID, domain.StatusProcessing, 0); err != nil {
+	// Update request status to processing, recording which attempt this is
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	if err := qs.requestRepo.StartAttempt(ctx, requestID, retried+1, maxRetry+1); err != nil {
+		if errors.Is(err, domain.ErrInvalidState) || errors.Is(err, domain.ErrNotFound) {
+			// Cancelled or deleted between enqueue and pickup
+			qs.logger.Info("skipping cancelled request", zap.String("request_id", requestID.String()))
+			return nil
+		}
 		qs.logger.Error("failed to update status to processing", zap.Error(err))
 		// Continue anyway
 	}
 
+	// A cancel request from the API cancels ctx through asynq. As a fallback, a progress
+	// update that finds the request cancelled stops the translation as well.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Execute translation with progress callback
 	progressCallback := func(progress int, message string) {
 		qs.logger.Info("translation progress",
@@ -133,12 +177,16 @@ func (qs *queueServer) handleTranslationTask(ctx context.Context, task *asynq.Ta
 		// Update database with progress
 		if progress >= 0 {
 			if err := qs.requestRepo.UpdateStatus(ctx, requestID, domain.StatusProcessing, progress); err != nil {
+				if errors.Is(err, domain.ErrInvalidState) || errors.Is(err, domain.ErrNotFound) {
+					cancel()
+					return
+				}
 				qs.logger.Error("failed to update progress", zap.Error(err))
 			}
 		}
 
 		// Publish to Redis pub/sub for SSE
-		update := pubsub.ProgressUpdate{
+		update := ports.ProgressUpdate{
 			RequestID: requestID,
 			Status:    string(domain.StatusProcessing),
 			Progress:  progress,
@@ -154,112 +202,606 @@ func (qs *queueServer) handleTranslationTask(ctx context.Context, task *asynq.Ta
 		}
 	}
 
-	output, err := qs.executor.Translate(ctx, payload.FilePath, progressCallback)
+	// Validate and extract the original archive before spending any time on translation.
+	// A rejected archive will never succeed, so it is not retried.
+	if payload.FileType == string(domain.FileTypeZip) {
+		if err := qs.extractOriginals(ctx, requestID, payload); err != nil {
+			if errors.Is(err, domain.ErrInvalidArchive) {
+				qs.failRequest(ctx, requestID, err)
+				return fmt.Errorf("invalid archive: %v: %w", err, asynq.SkipRetry)
+			}
+			return qs.attemptFailed(ctx, task, requestID, fmt.Errorf("failed to extract original archive: %w", err))
+		}
+	}
+
+	// Track every page so the worker can report them one by one
+	pageNumbers, err := qs.trackPages(ctx, requestID, payload)
 	if err != nil {
-		qs.logger.Error("translation failed",
-			zap.String("request_id", requestID.String()),
-			zap.Error(err),
-		)
+		return qs.attemptFailed(ctx, task, requestID, fmt.Errorf("failed to track pages: %w", err))
+	}
 
-		// Update request with error
-		req, _ := qs.requestRepo.GetByID(ctx, requestID)
-		if req != nil {
-			req.SetError(err.Error())
-			qs.requestRepo.Update(ctx, req)
+	pageCallback := func(update ports.PageUpdate) {
+		pageNumber, ok := pageNumbers[update.Name]
+		if !ok {
+			qs.logger.Debug("worker reported an unknown page",
+				zap.String("request_id", requestID.String()),
+				zap.String("page", update.Name),
+			)
+			return
 		}
 
-		// Publish error event
-		errorUpdate := pubsub.ProgressUpdate{
-			RequestID: requestID,
-			Status:    string(domain.StatusFailed),
-			Progress:  0,
-			Message:   fmt.Sprintf("Translation failed: %s", err.Error()),
+		var errorMessage *string
+		if update.Error != "" {
+			errorMessage = &update.Error
+		}
+		if err := qs.pageRepo.UpdateStatus(ctx, requestID, pageNumber, update.Status, errorMessage); err != nil {
+			qs.logger.Error("failed to update page status", zap.Error(err))
 		}
-		if pubErr := qs.publisher.PublishProgress(ctx, errorUpdate); pubErr != nil {
-			qs.logger.Error("failed to publish error", zap.Error(pubErr))
+	}
+
+	// The job is stopped once it runs past its deadline or stops reporting progress
+	watch := qs.jobLimits.watch(ctx, len(pageNumbers))
+	output, err := qs.executor.Translate(watch.ctx, requestID, payload.FilePath, payload.Options,
+		func(progress int, message string) {
+			watch.alive()
+			progressCallback(progress, message)
+		},
+		func(update ports.PageUpdate) {
+			watch.alive()
+			pageCallback(update)
+		},
+	)
+	watch.stop()
+	if err != nil {
+		if qs.isCancelled(ctx, requestID) {
+			qs.logger.Info("translation cancelled", zap.String("request_id", requestID.String()))
+			return nil
 		}
+		err = watch.err(err)
+
+		qs.logger.Error("translation failed",
+			zap.String("request_id", requestID.String()),
+			zap.Error(err),
+		)
 
-		return fmt.Errorf("translation failed: %w", err)
+		err = fmt.Errorf("translation failed: %w", err)
+		var workerErr *domain.WorkerError
+		if errors.As(err, &workerErr) && !workerErr.Retryable() {
+			// The worker rejected the input itself, another attempt would too
+			err = fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+		return qs.attemptFailed(ctx, task, requestID, err)
 	}
 
 	// Process output files
-	if err := qs.processOutputFiles(ctx, requestID, payload.FileType, output); err != nil {
+	results, err := qs.processOutputFiles(ctx, requestID, payload, output)
+	if err != nil {
 		qs.logger.Error("failed to process output files",
 			zap.String("request_id", requestID.String()),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to process output: %w", err)
+		return qs.attemptFailed(ctx, task, requestID, fmt.Errorf("failed to process output: %w", err))
+	}
+
+	summary, err := qs.finishPages(ctx, requestID, results)
+	if err != nil {
+		return qs.attemptFailed(ctx, task, requestID, fmt.Errorf("failed to record page status: %w", err))
+	}
+
+	// Some pages missing make the request partial, none translated fails it
+	status := summary.CompletionStatus()
+	if status == domain.StatusFailed {
+		err := fmt.Errorf("none of the %d pages could be translated (%d skipped, %d failed)",
+			summary.Total, summary.Skipped, summary.Failed)
+		if summary.Skipped == summary.Total {
+			// Unreadable pages stay unreadable
+			err = fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+		return qs.attemptFailed(ctx, task, requestID, err)
 	}
 
 	// Update request to completed
-	if err := qs.requestRepo.UpdateStatus(ctx, requestID, domain.StatusCompleted, 100); err != nil {
+	if err := qs.requestRepo.UpdateStatus(ctx, requestID, status, 100); err != nil {
+		if errors.Is(err, domain.ErrInvalidState) {
+			qs.logger.Info("request cancelled before completion", zap.String("request_id", requestID.String()))
+			return nil
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			// Deleted while translating, drop the files written since
+			qs.logger.Info("request deleted before completion", zap.String("request_id", requestID.String()))
+			qs.removeOutputs(requestID)
+			return nil
+		}
 		qs.logger.Error("failed to update status to completed", zap.Error(err))
-		return fmt.Errorf("failed to update status: %w", err)
+		return qs.attemptFailed(ctx, task, requestID, fmt.Errorf("failed to update status: %w", err))
 	}
 
 	// Publish completion event
-	completeUpdate := pubsub.ProgressUpdate{
+	message := "Translation completed successfully"
+	if status == domain.StatusPartial {
+		message = fmt.Sprintf("Translation completed with %d of %d pages", summary.Done, summary.Total)
+	}
+	completeUpdate := ports.ProgressUpdate{
 		RequestID: requestID,
-		Status:    string(domain.StatusCompleted),
+		Status:    string(status),
 		Progress:  100,
-		Message:   "Translation completed successfully",
+		Message:   message,
 	}
 	if err := qs.publisher.PublishProgress(ctx, completeUpdate); err != nil {
 		qs.logger.Error("failed to publish completion", zap.Error(err))
 	}
 
+	// Every page is in storage now, a later retry starts over
+	if err := os.RemoveAll(filepath.Join(qs.storagePath, "checkpoints", requestID.String())); err != nil {
+		qs.logger.Warn("failed to remove checkpoints",
+			zap.String("request_id", requestID.String()),
+			zap.Error(err),
+		)
+	}
+
 	qs.logger.Info("translation task completed",
 		zap.String("request_id", requestID.String()),
+		zap.String("status", string(status)),
+		zap.Int("pages", summary.Total),
+		zap.Int("skipped", summary.Skipped),
+		zap.Int("failed", summary.Failed),
+	)
+
+	return nil
+}
+
+// handlePageTranslationTask re-translates one page of a completed request and replaces
+// its translated file and result in place. Progress is published on the request's channel
+// with the page number set.
+func (qs *queueServer) handlePageTranslationTask(ctx context.Context, task *asynq.Task) error {
+	var payload PageTranslationPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	requestID := payload.RequestID
+	page := payload.PageNumber
+	qs.logger.Info("processing page translation task",
+		zap.String("request_id", requestID.String()),
+		zap.Int("page", page),
+		zap.Any("options", payload.Options),
+	)
+
+	if !qs.isStillCompleted(ctx, requestID) {
+		qs.logger.Info("skipping page of a request that is no longer completed",
+			zap.String("request_id", requestID.String()),
+			zap.Int("page", page),
+		)
+		return nil
+	}
+
+	publishUpdate := func(update ports.ProgressUpdate) {
+		update.RequestID = requestID
+		update.Page = page
+		if err := qs.publisher.PublishProgress(ctx, update); err != nil {
+			qs.logger.Error("failed to publish page progress", zap.Error(err))
+		}
+	}
+	publish := func(status domain.RequestStatus, progress int, message string) {
+		publishUpdate(ports.ProgressUpdate{Status: string(status), Progress: progress, Message: message})
+	}
+
+	// The request stays completed, so the page events are all that tells clients whether
+	// asynq runs the page again
+	fail := func(err error) error {
+		nextAttemptAt, retrying := nextAttempt(ctx, task, err)
+		if !retrying {
+			qs.logger.Error("page translation failed",
+				zap.String("request_id", requestID.String()),
+				zap.Int("page", page),
+				zap.Error(err),
+			)
+			publish(domain.StatusFailed, 0, fmt.Sprintf("Page translation failed: %s", err.Error()))
+			return err
+		}
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		qs.logger.Warn("page translation attempt failed, retrying",
+			zap.String("request_id", requestID.String()),
+			zap.Int("page", page),
+			zap.Int("attempt", retried+1),
+			zap.Int("max_attempts", maxRetry+1),
+			zap.Time("next_attempt_at", nextAttemptAt),
+			zap.Error(err),
+		)
+		publishUpdate(ports.ProgressUpdate{
+			Status:        string(domain.StatusRetrying),
+			Message:       fmt.Sprintf("Page %d attempt %d of %d failed, retrying: %s", page, retried+1, maxRetry+1, err.Error()),
+			Attempt:       retried + 1,
+			NextAttemptAt: &nextAttemptAt,
+		})
+		return err
+	}
+
+	result, err := qs.resultRepo.GetByPage(ctx, requestID, page)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fail(fmt.Errorf("page %d not found: %w", page, asynq.SkipRetry))
+		}
+		return fail(fmt.Errorf("failed to get page: %w", err))
+	}
+
+	originalRel, ok := domain.FileRelPath(requestID, "originals", result.OriginalPath)
+	if !ok {
+		return fail(fmt.Errorf("page %d has no original: %w", page, asynq.SkipRetry))
+	}
+	originalPath, err := archive.SafeJoin(filepath.Join(qs.storagePath, "originals", requestID.String()), originalRel)
+	if err != nil {
+		return fail(fmt.Errorf("invalid original path: %v: %w", err, asynq.SkipRetry))
+	}
+
+	// The worker names its output after the input file, so it gets a copy with a unique name
+	tempDir := filepath.Join(qs.storagePath, "temp", requestID.String(), fmt.Sprintf("page-%d", page))
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return fail(fmt.Errorf("failed to create temp directory: %w", err))
+	}
+	defer func() {
+		os.RemoveAll(tempDir)
+		os.Remove(filepath.Dir(tempDir))
+	}()
+
+	inputPath := filepath.Join(tempDir, fmt.Sprintf("%s_page_%d%s", requestID, page, path.Ext(originalRel)))
+	if err := copyFile(originalPath, inputPath); err != nil {
+		return fail(fmt.Errorf("failed to copy original: %w", err))
+	}
+
+	publish(domain.StatusProcessing, 0, fmt.Sprintf("Re-translating page %d", page))
+	watch := qs.jobLimits.watch(ctx, 1)
+	progressCallback := func(progress int, message string) {
+		watch.alive()
+		if progress >= 0 {
+			publish(domain.StatusProcessing, progress, message)
+		}
+	}
+
+	pageCallback := func(ports.PageUpdate) { watch.alive() }
+
+	output, err := qs.executor.Translate(watch.ctx, requestID, inputPath, payload.Options, progressCallback, pageCallback)
+	watch.stop()
+	if err != nil {
+		return fail(fmt.Errorf("page translation failed: %w", watch.err(err)))
+	}
+	// Single pages are written to the worker directory, not next to the input
+	defer os.Remove(output.OutputPath)
+	if len(output.Pages) == 0 {
+		return fail(fmt.Errorf("worker produced no page"))
+	}
+
+	// Replace the previous translation, or add one next to the other pages if it was missing
+	translatedRel, ok := domain.FileRelPath(requestID, "translated", result.TranslatedPath)
+	if !ok {
+		translatedRel = pageKey(originalRel) + ".jpg"
+	}
+	translatedPath, err := archive.SafeJoin(filepath.Join(qs.storagePath, "translated", requestID.String()), translatedRel)
+	if err != nil {
+		return fail(fmt.Errorf("invalid translated path: %v: %w", err, asynq.SkipRetry))
+	}
+
+	// A retry or delete of the whole request wins over the page
+	if !qs.isStillCompleted(ctx, requestID) {
+		qs.logger.Info("discarding page of a request that is no longer completed",
+			zap.String("request_id", requestID.String()),
+			zap.Int("page", page),
+		)
+		return nil
+	}
+
+	if err := replaceFile(output.Pages[0].TranslatedPath, translatedPath); err != nil {
+		return fail(fmt.Errorf("failed to store translated page: %w", err))
+	}
+
+	result.TranslatedPath = domain.FileURL(requestID, "translated", translatedRel)
+	result.Missing = false
+	if err := qs.resultRepo.Update(ctx, result); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return fail(fmt.Errorf("failed to update result: %w", err))
+	}
+
+	if err := qs.pageRepo.UpdateStatus(ctx, requestID, page, domain.PageStatusDone, nil); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		qs.logger.Error("failed to update page status", zap.Error(err))
+	}
+
+	publish(domain.StatusCompleted, 100, fmt.Sprintf("Page %d re-translated", page))
+	qs.completeIfAllPagesDone(ctx, requestID)
+
+	qs.logger.Info("page translation task completed",
+		zap.String("request_id", requestID.String()),
+		zap.Int("page", page),
+	)
+
+	return nil
+}
+
+// isStillCompleted reports whether a request exists and is still completed or partial
+func (qs *queueServer) isStillCompleted(ctx context.Context, requestID uuid.UUID) bool {
+	req, err := qs.requestRepo.GetByID(ctx, requestID)
+	return err == nil && req.Status.HasResults()
+}
+
+// completeIfAllPagesDone moves a partial request to completed once every page is translated
+func (qs *queueServer) completeIfAllPagesDone(ctx context.Context, requestID uuid.UUID) {
+	pages, err := qs.pageRepo.GetByRequestID(ctx, requestID)
+	if err != nil {
+		qs.logger.Error("failed to get pages", zap.Error(err))
+		return
+	}
+	if len(pages) == 0 || domain.SummarizePages(pages).CompletionStatus() != domain.StatusCompleted {
+		return
+	}
+
+	err = qs.requestRepo.TransitionStatus(ctx, requestID, []domain.RequestStatus{domain.StatusPartial}, domain.StatusCompleted)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidState) && !errors.Is(err, domain.ErrNotFound) {
+			qs.logger.Error("failed to complete request", zap.Error(err))
+		}
+		return
+	}
+
+	update := ports.ProgressUpdate{
+		RequestID: requestID,
+		Status:    string(domain.StatusCompleted),
+		Progress:  100,
+		Message:   "All pages translated",
+	}
+	if err := qs.publisher.PublishProgress(ctx, update); err != nil {
+		qs.logger.Error("failed to publish completion", zap.Error(err))
+	}
+}
+
+// handleCleanupTask applies the storage retention policies
+func (qs *queueServer) handleCleanupTask(ctx context.Context, task *asynq.Task) error {
+	if _, err := qs.cleaner.Cleanup(ctx); err != nil {
+		return fmt.Errorf("storage cleanup failed: %w", err)
+	}
+	return nil
+}
+
+// handleReconcileTask repairs requests whose task was lost or gave up
+func (qs *queueServer) handleReconcileTask(ctx context.Context, task *asynq.Task) error {
+	if _, err := qs.reconciler.Reconcile(ctx, false); err != nil {
+		return fmt.Errorf("reconcile failed: %w", err)
+	}
+	return nil
+}
+
+// attemptFailed handles a failed attempt of a translation task and returns err for asynq.
+// Unless this was the last attempt or err is marked asynq.SkipRetry, the request is
+// retrying until asynq runs the next attempt, instead of showing as failed in between.
+func (qs *queueServer) attemptFailed(ctx context.Context, task *asynq.Task, requestID uuid.UUID, err error) error {
+	nextAttemptAt, retrying := nextAttempt(ctx, task, err)
+	if !retrying {
+		qs.failRequest(ctx, requestID, err)
+		return err
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	if retryErr := qs.requestRepo.ScheduleRetry(ctx, requestID, err.Error(), nextAttemptAt); retryErr != nil {
+		if errors.Is(retryErr, domain.ErrInvalidState) || errors.Is(retryErr, domain.ErrNotFound) {
+			// Cancelled or deleted meanwhile, no need to run again
+			return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		}
+		qs.logger.Error("failed to mark request retrying", zap.Error(retryErr))
+	}
+
+	qs.logger.Warn("translation attempt failed, retrying",
+		zap.String("request_id", requestID.String()),
+		zap.Int("attempt", retried+1),
+		zap.Int("max_attempts", maxRetry+1),
+		zap.Time("next_attempt_at", nextAttemptAt),
+		zap.Error(err),
 	)
 
+	retryUpdate := ports.ProgressUpdate{
+		RequestID:     requestID,
+		Status:        string(domain.StatusRetrying),
+		Progress:      0,
+		Message:       fmt.Sprintf("Attempt %d of %d failed, retrying: %s", retried+1, maxRetry+1, err.Error()),
+		Attempt:       retried + 1,
+		NextAttemptAt: &nextAttemptAt,
+	}
+	if pubErr := qs.publisher.PublishProgress(ctx, retryUpdate); pubErr != nil {
+		qs.logger.Error("failed to publish retry", zap.Error(pubErr))
+	}
+
+	return err
+}
+
+// nextAttempt returns when asynq runs the task again after it failed with err. It reports
+// false when this was the last attempt or err is marked asynq.SkipRetry.
+func nextAttempt(ctx context.Context, task *asynq.Task, err error) (time.Time, bool) {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	if retried >= maxRetry || errors.Is(err, asynq.SkipRetry) {
+		return time.Time{}, false
+	}
+	// Same delay as asynq computes for the retry, see retryDelay
+	return time.Now().Add(retryDelay(retried, err, task)), true
+}
+
+// retryDelay is the asynq retry delay of every task: retryBaseDelay doubled after
+// every failed attempt, up to retryMaxDelay. Unlike asynq's default it has no jitter, so
+// the time published for the next attempt is when asynq actually runs it.
+func retryDelay(retried int, _ error, _ *asynq.Task) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < retried && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// failRequest records err on the request and publishes the failure event
+func (qs *queueServer) failRequest(ctx context.Context, requestID uuid.UUID, err error) {
+	// Update request with error
+	if failErr := qs.requestRepo.Fail(ctx, requestID, err.Error()); failErr != nil {
+		if errors.Is(failErr, domain.ErrInvalidState) || errors.Is(failErr, domain.ErrNotFound) {
+			// Cancelled or deleted meanwhile
+			return
+		}
+		qs.logger.Error("failed to mark request failed", zap.Error(failErr))
+	}
+
+	// Publish error event
+	errorUpdate := ports.ProgressUpdate{
+		RequestID: requestID,
+		Status:    string(domain.StatusFailed),
+		Progress:  0,
+		Message:   fmt.Sprintf("Translation failed: %s", err.Error()),
+	}
+	if pubErr := qs.publisher.PublishProgress(ctx, errorUpdate); pubErr != nil {
+		qs.logger.Error("failed to publish error", zap.Error(pubErr))
+	}
+}
+
+// isCancelled reports whether the request was cancelled or deleted through the API.
+// ctx may already be cancelled, so the lookup does not inherit its cancellation.
+func (qs *queueServer) isCancelled(ctx context.Context, requestID uuid.UUID) bool {
+	req, err := qs.requestRepo.GetByID(context.WithoutCancel(ctx), requestID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true
+	}
+	return err == nil && req.Status == domain.StatusCancelled
+}
+
+// trackPages records the pages of a request as pending before the worker starts and returns
+// their numbers by the name the worker reports them under. Archive pages are numbered in
+// natural order, like their results.
+func (qs *queueServer) trackPages(ctx context.Context, requestID uuid.UUID, payload TranslationPayload) (map[string]int, error) {
+	var names []string
+	if payload.FileType == string(domain.FileTypeZip) {
+		// Without extracted originals pages are only known once the results are in
+		names, _ = collectImageFiles(filepath.Join(qs.storagePath, "originals", requestID.String()))
+		natsort.Sort(names)
+	} else {
+		names = []string{filepath.Base(payload.FilePath)}
+	}
+
+	pages := make([]*domain.Page, 0, len(names))
+	pageNumbers := make(map[string]int, len(names))
+	for i, name := range names {
+		pages = append(pages, domain.NewPage(requestID, i+1, name))
+		pageNumbers[name] = i + 1
+	}
+
+	if err := qs.pageRepo.Replace(ctx, requestID, pages); err != nil {
+		return nil, err
+	}
+	return pageNumbers, nil
+}
+
+// finishPages settles the status of every page once the results are saved. A page with a
+// translation is done; one without keeps the reason the worker gave, or fails if it gave none.
+func (qs *queueServer) finishPages(ctx context.Context, requestID uuid.UUID, results []*domain.Result) (domain.PageSummary, error) {
+	reported, err := qs.pageRepo.GetByRequestID(ctx, requestID)
+	if err != nil {
+		return domain.PageSummary{}, err
+	}
+	reportedByName := make(map[string]*domain.Page, len(reported))
+	for _, page := range reported {
+		reportedByName[page.Name] = page
+	}
+
+	pages := make([]*domain.Page, 0, len(results))
+	for _, result := range results {
+		name, ok := domain.FileRelPath(requestID, "originals", result.OriginalPath)
+		if !ok {
+			name, _ = domain.FileRelPath(requestID, "translated", result.TranslatedPath)
+		}
+
+		page := domain.NewPage(requestID, result.PageNumber, name)
+		switch previous := reportedByName[name]; {
+		case !result.Missing:
+			page.Status = domain.PageStatusDone
+		case previous != nil && (previous.Status == domain.PageStatusSkipped || previous.Status == domain.PageStatusFailed):
+			page.Status = previous.Status
+			page.ErrorMessage = previous.ErrorMessage
+		default:
+			message := "the worker produced no translated page"
+			page.Status = domain.PageStatusFailed
+			page.ErrorMessage = &message
+		}
+		pages = append(pages, page)
+	}
+
+	if err := qs.pageRepo.Replace(ctx, requestID, pages); err != nil {
+		return domain.PageSummary{}, err
+	}
+	return domain.SummarizePages(pages), nil
+}
+
+// removeOutputs deletes the page directories written for a request
+func (qs *queueServer) removeOutputs(requestID uuid.UUID) {
+	for _, dir := range []string{"originals", "translated", "checkpoints"} {
+		os.RemoveAll(filepath.Join(qs.storagePath, dir, requestID.String()))
+	}
+}
+
+// extractOriginals extracts the uploaded archive into storage/originals/<id>.
+// The archive is looked up by name in the local uploads directory since payload paths
+// may use the API container's storage prefix.
+func (qs *queueServer) extractOriginals(ctx context.Context, requestID uuid.UUID, payload TranslationPayload) error {
+	originalsDir := filepath.Join(qs.storagePath, "originals", requestID.String())
+	originalZipPath := filepath.Join(qs.storagePath, "uploads", requestID.String(), filepath.Base(payload.FilePath))
+	if _, err := os.Stat(originalZipPath); err != nil {
+		qs.logger.Warn("original archive not found", zap.String("path", originalZipPath))
+		return nil
+	}
+
+	// Start from a clean directory so a retried task does not collide with a previous attempt
+	if err := os.RemoveAll(originalsDir); err != nil {
+		return fmt.Errorf("failed to clean originals directory: %w", err)
+	}
+	if err := os.MkdirAll(originalsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", originalsDir, err)
+	}
+
+	if _, err := archive.Extract(ctx, originalZipPath, archive.FormatZip, originalsDir, qs.limits, nil); err != nil {
+		qs.logger.Warn("rejected original archive",
+			zap.String("request_id", requestID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	qs.logger.Info("extracted original zip", zap.String("path", originalsDir))
 	return nil
 }
 
 func (qs *queueServer) processOutputFiles(
 	ctx context.Context,
 	requestID uuid.UUID,
-	fileType string,
+	payload TranslationPayload,
 	output *ports.TranslationOutput,
-) error {
+) ([]*domain.Result, error) {
 	// Create output directories
 	originalsDir := filepath.Join(qs.storagePath, "originals", requestID.String())
 	translatedDir := filepath.Join(qs.storagePath, "translated", requestID.String())
 
 	for _, dir := range []string{originalsDir, translatedDir} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
 
-	// For ZIP files, extract and organize pages
-	if fileType == "zip" {
-		// Extract original ZIP to originals directory
-		uploadPath := filepath.Join(qs.storagePath, "uploads", requestID.String())
-		originalZipPath := ""
-
-		// Find the original zip in uploads
-		entries, err := os.ReadDir(uploadPath)
-		if err == nil {
-			for _, entry := range entries {
-				if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".zip") {
-					originalZipPath = filepath.Join(uploadPath, entry.Name())
-					break
-				}
-			}
+	// For ZIP files, extract and organize pages. Originals were extracted before translation.
+	if payload.FileType == string(domain.FileTypeZip) {
+		// The translated archive comes from the worker but goes through the same safe reader
+		if err := os.RemoveAll(translatedDir); err != nil {
+			return nil, fmt.Errorf("failed to clean translated directory: %w", err)
 		}
-
-		// Extract original ZIP
-		if originalZipPath != "" {
-			if err := extractZip(originalZipPath, originalsDir); err != nil {
-				qs.logger.Error("failed to extract original zip", zap.Error(err))
-			} else {
-				qs.logger.Info("extracted original zip", zap.String("path", originalsDir))
-			}
+		if err := os.MkdirAll(translatedDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", translatedDir, err)
 		}
-
-		// Extract translated ZIP
-		if err := extractZip(output.OutputPath, translatedDir); err != nil {
-			return fmt.Errorf("failed to extract translated zip: %w", err)
+		if _, err := archive.Extract(ctx, output.OutputPath, archive.FormatZip, translatedDir, qs.limits, nil); err != nil {
+			return nil, fmt.Errorf("failed to extract translated zip: %w", err)
 		}
 
 		qs.logger.Info("extracted translated zip", zap.String("path", translatedDir))
@@ -267,43 +809,25 @@ func (qs *queueServer) processOutputFiles(
 		// Collect all image files from extracted directories
 		originalFiles, err := collectImageFiles(originalsDir)
 		if err != nil {
-			return fmt.Errorf("failed to collect original images: %w", err)
+			return nil, fmt.Errorf("failed to collect original images: %w", err)
 		}
 
 		translatedFiles, err := collectImageFiles(translatedDir)
 		if err != nil {
-			return fmt.Errorf("failed to collect translated images: %w", err)
+			return nil, fmt.Errorf("failed to collect translated images: %w", err)
 		}
 
-		// Sort files to match pages
-		sort.Strings(originalFiles)
-		sort.Strings(translatedFiles)
-
-		// Create result entries
-		results := make([]*domain.Result, 0, len(translatedFiles))
-		for i, translatedFile := range translatedFiles {
-			originalFile := ""
-			if i < len(originalFiles) {
-				originalFile = originalFiles[i]
-			}
-
-			// Create API paths using relative paths from the base directory
-			originalAPIPath := ""
-			if originalFile != "" {
-				relPath, _ := filepath.Rel(originalsDir, originalFile)
-				originalAPIPath = fmt.Sprintf("/api/files/%s/originals/%s", requestID, filepath.ToSlash(relPath))
-			}
-			relPath, _ := filepath.Rel(translatedDir, translatedFile)
-			translatedAPIPath := fmt.Sprintf("/api/files/%s/translated/%s", requestID, filepath.ToSlash(relPath))
-
-			result := domain.NewResult(requestID, i+1, originalAPIPath, translatedAPIPath)
-			results = append(results, result)
-		}
+		// Create result entries, one per original page
+		results := pairPages(requestID, originalFiles, translatedFiles)
 
 		// Save results to database
 		if len(results) > 0 {
+			// A previous attempt may have saved results before failing
+			if err := qs.resultRepo.DeleteByRequestID(ctx, requestID); err != nil {
+				return nil, fmt.Errorf("failed to delete previous results: %w", err)
+			}
 			if err := qs.resultRepo.CreateBatch(ctx, results); err != nil {
-				return fmt.Errorf("failed to save results: %w", err)
+				return nil, fmt.Errorf("failed to save results: %w", err)
 			}
 
 			// Update page count
@@ -314,12 +838,19 @@ func (qs *queueServer) processOutputFiles(
 			}
 		}
 
+		missing := 0
+		for _, result := range results {
+			if result.Missing {
+				missing++
+			}
+		}
 		qs.logger.Info("processed zip output",
 			zap.String("request_id", requestID.String()),
 			zap.Int("pages", len(results)),
+			zap.Int("missing", missing),
 		)
 
-		return nil
+		return results, nil
 	}
 
 	// For single images, create result entries
@@ -330,16 +861,16 @@ func (qs *queueServer) processOutputFiles(
 		translatedDest := filepath.Join(translatedDir, filepath.Base(page.TranslatedPath))
 
 		if err := copyFile(page.OriginalPath, originalDest); err != nil {
-			return fmt.Errorf("failed to copy original: %w", err)
+			return nil, fmt.Errorf("failed to copy original: %w", err)
 		}
 
 		if err := copyFile(page.TranslatedPath, translatedDest); err != nil {
-			return fmt.Errorf("failed to copy translated: %w", err)
+			return nil, fmt.Errorf("failed to copy translated: %w", err)
 		}
 
 		// Create API paths
-		originalAPIPath := fmt.Sprintf("/api/files/%s/originals/%s", requestID, filepath.Base(originalDest))
-		translatedAPIPath := fmt.Sprintf("/api/files/%s/translated/%s", requestID, filepath.Base(translatedDest))
+		originalAPIPath := domain.FileURL(requestID, "originals", filepath.Base(originalDest))
+		translatedAPIPath := domain.FileURL(requestID, "translated", filepath.Base(translatedDest))
 
 		result := domain.NewResult(requestID, page.PageNumber, originalAPIPath, translatedAPIPath)
 		results = append(results, result)
@@ -347,8 +878,11 @@ func (qs *queueServer) processOutputFiles(
 
 	// Save results to database
 	if len(results) > 0 {
+		if err := qs.resultRepo.DeleteByRequestID(ctx, requestID); err != nil {
+			return nil, fmt.Errorf("failed to delete previous results: %w", err)
+		}
 		if err := qs.resultRepo.CreateBatch(ctx, results); err != nil {
-			return fmt.Errorf("failed to save results: %w", err)
+			return nil, fmt.Errorf("failed to save results: %w", err)
 		}
 
 		// Update page count
@@ -359,7 +893,7 @@ func (qs *queueServer) processOutputFiles(
 		}
 	}
 
-	return nil
+	return results, nil
 }
 
 // copyFile copies a file from src to dst
@@ -371,53 +905,67 @@ func copyFile(src, dst string) error {
 	return os.WriteFile(dst, input, 0644)
 }
 
-// extractZip extracts a ZIP archive to the specified directory
-func extractZip(zipPath, destDir string) error {
-	reader, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to open zip: %w", err)
+// replaceFile copies src over dst through a temporary file, so readers never see a partial page
+func replaceFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
 	}
-	defer reader.Close()
-
-	for _, file := range reader.File {
-		// Skip directories
-		if file.FileInfo().IsDir() {
-			continue
-		}
+	tmp := dst + ".tmp"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
 
-		// Create destination path
-		destPath := filepath.Join(destDir, file.Name)
+// pairPages matches original and translated pages by relative path without extension,
+// since the worker re-encodes every page as JPEG. Pages are numbered in natural order of the
+// originals; an original without translation becomes a missing result. Translations without
+// an original (e.g. when the uploaded archive is gone) are appended after them.
+func pairPages(requestID uuid.UUID, originalFiles, translatedFiles []string) []*domain.Result {
+	translatedByKey := make(map[string]string, len(translatedFiles))
+	for _, translatedFile := range translatedFiles {
+		translatedByKey[pageKey(translatedFile)] = translatedFile
+	}
 
-		// Create parent directories
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
+	natsort.Sort(originalFiles)
+	natsort.Sort(translatedFiles)
 
-		// Extract file
-		rc, err := file.Open()
-		if err != nil {
-			return fmt.Errorf("failed to open file in zip: %w", err)
-		}
+	results := make([]*domain.Result, 0, len(originalFiles))
+	paired := make(map[string]bool, len(originalFiles))
+	for _, originalFile := range originalFiles {
+		key := pageKey(originalFile)
+		originalAPIPath := domain.FileURL(requestID, "originals", originalFile)
 
-		outFile, err := os.Create(destPath)
-		if err != nil {
-			rc.Close()
-			return fmt.Errorf("failed to create output file: %w", err)
+		translatedFile, ok := translatedByKey[key]
+		if !ok || paired[key] {
+			results = append(results, domain.NewMissingResult(requestID, len(results)+1, originalAPIPath))
+			continue
 		}
+		paired[key] = true
 
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
+		translatedAPIPath := domain.FileURL(requestID, "translated", translatedFile)
+		results = append(results, domain.NewResult(requestID, len(results)+1, originalAPIPath, translatedAPIPath))
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to extract file: %w", err)
+	for _, translatedFile := range translatedFiles {
+		if paired[pageKey(translatedFile)] {
+			continue
 		}
+		translatedAPIPath := domain.FileURL(requestID, "translated", translatedFile)
+		results = append(results, domain.NewResult(requestID, len(results)+1, "", translatedAPIPath))
 	}
 
-	return nil
+	return results
+}
+
+// pageKey identifies a page by its relative path with the extension removed
+func pageKey(relPath string) string {
+	return strings.TrimSuffix(relPath, path.Ext(relPath))
 }
 
-// collectImageFiles collects all image files from a directory
+// collectImageFiles collects all image files from a directory as slash-separated paths
+// relative to it. Hidden files and macOS resource forks are ignored.
 func collectImageFiles(dir string) ([]string, error) {
 	var files []string
 	validExts := map[string]bool{
@@ -428,15 +976,25 @@ func collectImageFiles(dir string) ([]string, error) {
 		".bmp":  true,
 	}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() {
-			ext := strings.ToLower(filepath.Ext(path))
-			if validExts[ext] {
-				files = append(files, path)
-			}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if strings.HasPrefix(relPath, "__MACOSX/") {
+			return nil
+		}
+
+		if validExts[strings.ToLower(path.Ext(relPath))] {
+			files = append(files, relPath)
 		}
 		return nil
 	})
@@ -0,0 +1,270 @@
+package asynq
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/archive"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/fake"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// memRequests keeps requests in memory and enforces the status transitions like the
+// Postgres repository. Methods the handler does not call are left to the nil interface.
+type memRequests struct {
+	ports.RequestRepository
+	mu       sync.Mutex
+	requests map[uuid.UUID]*domain.Request
+}
+
+func (r *memRequests) GetByID(ctx context.Context, id uuid.UUID) (*domain.Request, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *request
+	return &copied, nil
+}
+
+func (r *memRequests) Update(ctx context.Context, request *domain.Request) error {
+	return r.move(request.ID, request.Status, func(stored *domain.Request) { *stored = *request })
+}
+
+func (r *memRequests) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.RequestStatus, progress int) error {
+	return r.move(id, status, func(stored *domain.Request) { stored.Progress = progress })
+}
+
+func (r *memRequests) StartAttempt(ctx context.Context, id uuid.UUID, attempt, maxAttempts int) error {
+	return r.move(id, domain.StatusProcessing, func(stored *domain.Request) { stored.Progress = 0 })
+}
+
+func (r *memRequests) Fail(ctx context.Context, id uuid.UUID, message string) error {
+	return r.move(id, domain.StatusFailed, func(stored *domain.Request) { stored.ErrorMessage = &message })
+}
+
+// move changes the status of a stored request if it may move there, then applies fn
+func (r *memRequests) move(id uuid.UUID, to domain.RequestStatus, fn func(*domain.Request)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.requests[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Status != to {
+		if err := domain.ValidateTransition(stored.Status, to); err != nil {
+			return err
+		}
+	}
+	fn(stored)
+	stored.Status = to
+	return nil
+}
+
+type memResults struct {
+	ports.ResultRepository
+	results []*domain.Result
+}
+
+func (r *memResults) CreateBatch(ctx context.Context, results []*domain.Result) error {
+	r.results = append(r.results, results...)
+	return nil
+}
+
+func (r *memResults) DeleteByRequestID(ctx context.Context, requestID uuid.UUID) error {
+	r.results = nil
+	return nil
+}
+
+type memPages struct {
+	ports.PageRepository
+	mu    sync.Mutex
+	pages []*domain.Page
+}
+
+func (r *memPages) Replace(ctx context.Context, requestID uuid.UUID, pages []*domain.Page) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages = pages
+	return nil
+}
+
+func (r *memPages) GetByRequestID(ctx context.Context, requestID uuid.UUID) ([]*domain.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pages, nil
+}
+
+func (r *memPages) UpdateStatus(
+	ctx context.Context,
+	requestID uuid.UUID,
+	pageNumber int,
+	status domain.PageStatus,
+	errorMessage *string,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, page := range r.pages {
+		if page.PageNumber == pageNumber {
+			page.Status = status
+			page.ErrorMessage = errorMessage
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type recordingPublisher struct {
+	mu      sync.Mutex
+	updates []ports.ProgressUpdate
+}
+
+func (p *recordingPublisher) PublishProgress(ctx context.Context, update ports.ProgressUpdate) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updates = append(p.updates, update)
+	return nil
+}
+
+func TestHandleTranslationTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		fileType    domain.FileType
+		pages       map[string][]byte // archive content, or the image itself under filename
+		wantStatus  domain.RequestStatus
+		wantPages   map[string]domain.PageStatus
+		wantMissing []int // page numbers without a translation
+	}{
+		{
+			name:     "archive",
+			filename: "chapter.zip",
+			fileType: domain.FileTypeZip,
+			pages: map[string][]byte{
+				"ch1/001.png": testutil.PNGPage(t),
+				"ch1/010.png": testutil.PNGPage(t),
+				"ch1/002.png": testutil.PNGPage(t),
+			},
+			wantStatus: domain.StatusCompleted,
+			wantPages: map[string]domain.PageStatus{
+				"ch1/001.png": domain.PageStatusDone,
+				"ch1/002.png": domain.PageStatusDone,
+				"ch1/010.png": domain.PageStatusDone,
+			},
+		},
+		{
+			name:     "archive with an unreadable page",
+			filename: "chapter.zip",
+			fileType: domain.FileTypeZip,
+			pages: map[string][]byte{
+				"001.png": testutil.PNGPage(t),
+				"002.png": []byte("not an image"),
+			},
+			wantStatus: domain.StatusPartial,
+			wantPages: map[string]domain.PageStatus{
+				"001.png": domain.PageStatusDone,
+				"002.png": domain.PageStatusSkipped,
+			},
+			wantMissing: []int{2},
+		},
+		{
+			name:       "image",
+			filename:   "cover.png",
+			fileType:   domain.FileTypeImage,
+			pages:      map[string][]byte{"cover.png": testutil.PNGPage(t)},
+			wantStatus: domain.StatusCompleted,
+			wantPages:  map[string]domain.PageStatus{"cover.png": domain.PageStatusDone},
+		},
+	}
+
+	for _, tt := range tests {
+		storage := t.TempDir()
+		request := domain.NewRequest(tt.filename, tt.fileType)
+		uploadDir := filepath.Join(storage, "uploads", request.ID.String())
+		if err := os.MkdirAll(uploadDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		input := filepath.Join(uploadDir, tt.filename)
+		if tt.fileType == domain.FileTypeZip {
+			testutil.WriteZip(t, input, tt.pages)
+		} else if err := os.WriteFile(input, tt.pages[tt.filename], 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		requests := &memRequests{requests: map[uuid.UUID]*domain.Request{request.ID: request}}
+		results := &memResults{}
+		pages := &memPages{}
+		publisher := &recordingPublisher{}
+		qs := &queueServer{
+			logger:      zap.NewNop(),
+			requestRepo: requests,
+			resultRepo:  results,
+			pageRepo:    pages,
+			executor:    fake.NewExecutor(&config.WorkerConfig{}, zap.NewNop()),
+			storagePath: storage,
+			limits:      archive.Limits{MaxEntries: 100, MaxTotalSize: 1 << 30, MaxCompressionRatio: 1000},
+			jobLimits:   jobLimits{timeout: time.Minute, stall: time.Minute},
+			publisher:   publisher,
+		}
+
+		payload, _ := json.Marshal(TranslationPayload{
+			RequestID: request.ID,
+			FilePath:  input,
+			FileType:  string(tt.fileType.WorkerFileType()),
+		})
+		if err := qs.handleTranslationTask(context.Background(), asynq.NewTask(TaskTypeTranslation, payload)); err != nil {
+			t.Errorf("%s: handleTranslationTask: %v", tt.name, err)
+			continue
+		}
+
+		stored, _ := requests.GetByID(context.Background(), request.ID)
+		if stored.Status != tt.wantStatus || stored.Progress != 100 || stored.PageCount != len(tt.pages) {
+			t.Errorf("%s: request: got %s at %d%% with %d pages, want %s at 100%% with %d pages",
+				tt.name, stored.Status, stored.Progress, stored.PageCount, tt.wantStatus, len(tt.pages))
+		}
+
+		last := publisher.updates[len(publisher.updates)-1]
+		if last.Status != string(tt.wantStatus) || last.Progress != 100 {
+			t.Errorf("%s: last update: got %s at %d%%, want %s at 100%%", tt.name, last.Status, last.Progress, tt.wantStatus)
+		}
+
+		gotPages := make(map[string]domain.PageStatus, len(pages.pages))
+		for _, page := range pages.pages {
+			gotPages[page.Name] = page.Status
+		}
+		if !reflect.DeepEqual(gotPages, tt.wantPages) {
+			t.Errorf("%s: pages: got %v, want %v", tt.name, gotPages, tt.wantPages)
+		}
+
+		var missing []int
+		for _, result := range results.results {
+			if result.Missing {
+				missing = append(missing, result.PageNumber)
+				continue
+			}
+			rel, _ := domain.FileRelPath(request.ID, "translated", result.TranslatedPath)
+			if _, err := os.Stat(filepath.Join(storage, "translated", request.ID.String(), filepath.FromSlash(rel))); err != nil {
+				t.Errorf("%s: translated page %d: %v", tt.name, result.PageNumber, err)
+			}
+		}
+		sort.Ints(missing)
+		if len(results.results) != len(tt.pages) || !reflect.DeepEqual(missing, tt.wantMissing) {
+			t.Errorf("%s: results: got %d with missing pages %v, want %d with missing pages %v",
+				tt.name, len(results.results), missing, len(tt.pages), tt.wantMissing)
+		}
+	}
+}
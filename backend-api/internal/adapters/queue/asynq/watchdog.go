@@ -0,0 +1,85 @@
+package asynq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+)
+
+// jobLimits bounds the time a worker job may take. A zero timeout or stall disables that limit.
+type jobLimits struct {
+	timeout     time.Duration // base deadline of a job
+	pageTimeout time.Duration // added to the deadline for every page
+	stall       time.Duration // longest time without a progress or page report
+}
+
+func newJobLimits(cfg *config.WorkerConfig) jobLimits {
+	return jobLimits{
+		timeout:     cfg.Timeout,
+		pageTimeout: cfg.PageTimeout,
+		stall:       cfg.StallTimeout,
+	}
+}
+
+// deadline is how long a job over pages pages may run, 0 if it may run forever
+func (l jobLimits) deadline(pages int) time.Duration {
+	if l.timeout <= 0 {
+		return 0
+	}
+	return l.timeout + time.Duration(pages)*l.pageTimeout
+}
+
+// watchdog stops a worker job that runs past its deadline or stops reporting progress.
+// The job's context is cancelled with a *domain.WorkerTimeoutError as its cause.
+type watchdog struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stall  time.Duration
+	timers []*time.Timer
+}
+
+// watch derives the context of a job over pages pages from ctx.
+// alive must be called on every report of the worker, and stop once the job returned.
+func (l jobLimits) watch(ctx context.Context, pages int) *watchdog {
+	ctx, cancel := context.WithCancelCause(ctx)
+	w := &watchdog{ctx: ctx, cancel: cancel, stall: l.stall}
+
+	if deadline := l.deadline(pages); deadline > 0 {
+		w.timers = append(w.timers, time.AfterFunc(deadline, func() {
+			cancel(&domain.WorkerTimeoutError{Reason: domain.TimeoutReasonDeadline, Limit: deadline})
+		}))
+	}
+	if l.stall > 0 {
+		w.timers = append(w.timers, time.AfterFunc(l.stall, func() {
+			cancel(&domain.WorkerTimeoutError{Reason: domain.TimeoutReasonStalled, Limit: l.stall})
+		}))
+	}
+	return w
+}
+
+// alive restarts the stall timer
+func (w *watchdog) alive() {
+	if w.stall > 0 && w.ctx.Err() == nil {
+		w.timers[len(w.timers)-1].Reset(w.stall)
+	}
+}
+
+// stop releases the timers and the job's context
+func (w *watchdog) stop() {
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+	w.cancel(nil)
+}
+
+// err replaces the error of a job the watchdog stopped with the reason it was stopped
+func (w *watchdog) err(err error) error {
+	var timeoutErr *domain.WorkerTimeoutError
+	if errors.As(context.Cause(w.ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
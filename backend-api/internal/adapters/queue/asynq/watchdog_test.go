@@ -0,0 +1,73 @@
+package asynq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+)
+
+func TestJobLimitsDeadline(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits jobLimits
+		pages  int
+		want   time.Duration
+	}{
+		{name: "scales with pages", limits: jobLimits{timeout: 10 * time.Minute, pageTimeout: time.Minute}, pages: 40, want: 50 * time.Minute},
+		{name: "no pages", limits: jobLimits{timeout: 10 * time.Minute, pageTimeout: time.Minute}, want: 10 * time.Minute},
+		{name: "disabled", limits: jobLimits{pageTimeout: time.Minute}, pages: 40, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limits.deadline(tt.pages); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	jobErr := errors.New("worker process failed")
+	tests := []struct {
+		name       string
+		limits     jobLimits
+		reports    int // reports sent 10ms apart
+		wantReason string
+	}{
+		{name: "deadline", limits: jobLimits{timeout: 30 * time.Millisecond}, reports: 10, wantReason: domain.TimeoutReasonDeadline},
+		{name: "stalled", limits: jobLimits{stall: 30 * time.Millisecond}, wantReason: domain.TimeoutReasonStalled},
+		{name: "kept alive", limits: jobLimits{stall: 200 * time.Millisecond}, reports: 10},
+	}
+
+	for _, tt := range tests {
+		watch := tt.limits.watch(context.Background(), 1)
+		for i := 0; i < tt.reports; i++ {
+			time.Sleep(10 * time.Millisecond)
+			watch.alive()
+		}
+		if tt.wantReason != "" {
+			select {
+			case <-watch.ctx.Done():
+			case <-time.After(time.Second):
+				t.Errorf("%s: job was not stopped", tt.name)
+			}
+		}
+		stopped := watch.ctx.Err() != nil
+		watch.stop()
+
+		err := watch.err(jobErr)
+		var timeoutErr *domain.WorkerTimeoutError
+		switch {
+		case tt.wantReason == "":
+			if stopped || err != jobErr {
+				t.Errorf("%s: got %v (stopped %v), want the job's own error", tt.name, err, stopped)
+			}
+		case !errors.As(err, &timeoutErr) || timeoutErr.Reason != tt.wantReason:
+			t.Errorf("%s: got %v, want a %s timeout", tt.name, err, tt.wantReason)
+		case !errors.Is(err, domain.ErrWorkerTimeout):
+			t.Errorf("%s: %v does not match ErrWorkerTimeout", tt.name, err)
+		}
+	}
+}
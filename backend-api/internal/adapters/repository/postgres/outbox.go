@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type outboxRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new PostgreSQL outbox repository.
+// Tasks are added by RequestRepository.CreateWithTask.
+func NewOutboxRepository(db *pgxpool.Pool) ports.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxTask, error) {
+	// SKIP LOCKED lets several API instances dispatch without picking the same task
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, request_id, file_path, file_type, attempts, last_error, next_attempt_at, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*domain.OutboxTask
+	for rows.Next() {
+		var task domain.OutboxTask
+		err := rows.Scan(
+			&task.ID,
+			&task.RequestID,
+			&task.FilePath,
+			&task.FileType,
+			&task.Attempts,
+			&task.LastError,
+			&task.NextAttemptAt,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox task: %w", err)
+		}
+		tasks = append(tasks, &task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id int64, delay time.Duration, lastError string) error {
+	query := `
+		UPDATE outbox
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second', last_error = $3
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, delay.Seconds(), lastError); err != nil {
+		return fmt.Errorf("failed to reschedule outbox task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete outbox task: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type pageRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPageRepository creates a new PostgreSQL page repository
+func NewPageRepository(db *pgxpool.Pool) ports.PageRepository {
+	return &pageRepository{db: db}
+}
+
+func (r *pageRepository) Replace(ctx context.Context, requestID uuid.UUID, pages []*domain.Page) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM pages WHERE request_id = $1`, requestID); err != nil {
+		return fmt.Errorf("failed to delete pages: %w", err)
+	}
+
+	query := `
+		INSERT INTO pages (request_id, page_number, name, status, error_message, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for _, page := range pages {
+		_, err := tx.Exec(ctx, query,
+			requestID,
+			page.PageNumber,
+			page.Name,
+			page.Status,
+			page.ErrorMessage,
+			page.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert page: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *pageRepository) GetByRequestID(ctx context.Context, requestID uuid.UUID) ([]*domain.Page, error) {
+	query := `
+		SELECT request_id, page_number, name, status, error_message, updated_at
+		FROM pages
+		WHERE request_id = $1
+		ORDER BY page_number ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+	defer rows.Close()
+
+	pages := []*domain.Page{}
+	for rows.Next() {
+		var page domain.Page
+		err := rows.Scan(
+			&page.RequestID,
+			&page.PageNumber,
+			&page.Name,
+			&page.Status,
+			&page.ErrorMessage,
+			&page.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		pages = append(pages, &page)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pages: %w", err)
+	}
+
+	return pages, nil
+}
+
+func (r *pageRepository) UpdateStatus(
+	ctx context.Context,
+	requestID uuid.UUID,
+	pageNumber int,
+	status domain.PageStatus,
+	errorMessage *string,
+) error {
+	query := `
+		UPDATE pages
+		SET status = $1, error_message = $2, updated_at = NOW()
+		WHERE request_id = $3 AND page_number = $4
+	`
+
+	tag, err := r.db.Exec(ctx, query, status, errorMessage, requestID, pageNumber)
+	if err != nil {
+		return fmt.Errorf("failed to update page: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,23 +24,82 @@ func NewRequestRepository(db *pgxpool.Pool) ports.RequestRepository {
 	return &requestRepository{db: db}
 }
 
+// uniqueViolation is the SQLSTATE of an insert that breaks a unique index
+const uniqueViolation = "23505"
+
+// execer runs a statement on the pool or inside a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
 func (r *requestRepository) Create(ctx context.Context, request *domain.Request) error {
+	return insertRequest(ctx, r.db, request)
+}
+
+func (r *requestRepository) CreateWithTask(ctx context.Context, request *domain.Request, task *domain.OutboxTask) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertRequest(ctx, tx, request); err != nil {
+		return err
+	}
+	if err := insertOutboxTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func insertOutboxTask(ctx context.Context, tx pgx.Tx, task *domain.OutboxTask) error {
 	query := `
-		INSERT INTO requests (id, filename, file_type, status, progress, page_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO outbox (request_id, file_path, file_type)
+		VALUES ($1, $2, $3)
+		RETURNING id, next_attempt_at, created_at
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	err := tx.QueryRow(ctx, query, task.RequestID, task.FilePath, task.FileType).
+		Scan(&task.ID, &task.NextAttemptAt, &task.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox task: %w", err)
+	}
+
+	return nil
+}
+
+func insertRequest(ctx context.Context, db execer, request *domain.Request) error {
+	query := `
+		INSERT INTO requests (id, filename, file_type, status, priority, progress, page_count, options,
+		                      idempotency_key, idempotency_hash, idempotency_seq, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := db.Exec(ctx, query,
 		request.ID,
 		request.Filename,
 		request.FileType,
 		request.Status,
+		request.Priority,
 		request.Progress,
 		request.PageCount,
+		request.Options,
+		request.IdempotencyKey,
+		request.IdempotencyHash,
+		request.IdempotencySeq,
 		request.CreatedAt,
 		request.UpdatedAt,
 	)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "idx_requests_idempotency_key" {
+		return fmt.Errorf("failed to create request: %w", domain.ErrIdempotencyKeyTaken)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -47,8 +109,9 @@ func (r *requestRepository) Create(ctx context.Context, request *domain.Request)
 
 func (r *requestRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Request, error) {
 	query := `
-		SELECT id, filename, file_type, status, progress, page_count,
-		       thumbnail_path, error_message, created_at, updated_at, completed_at
+		SELECT id, filename, file_type, status, priority, progress, page_count,
+		       thumbnail_path, error_message, options, attempt, max_attempts, next_attempt_at,
+		       created_at, updated_at, completed_at
 		FROM requests
 		WHERE id = $1
 	`
@@ -59,10 +122,15 @@ func (r *requestRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 		&request.Filename,
 		&request.FileType,
 		&request.Status,
+		&request.Priority,
 		&request.Progress,
 		&request.PageCount,
 		&request.ThumbnailPath,
 		&request.ErrorMessage,
+		&request.Options,
+		&request.Attempt,
+		&request.MaxAttempts,
+		&request.NextAttemptAt,
 		&request.CreatedAt,
 		&request.UpdatedAt,
 		&request.CompletedAt,
@@ -81,8 +149,9 @@ func (r *requestRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 func (r *requestRepository) List(ctx context.Context, filter ports.RequestFilter) ([]*domain.Request, int, error) {
 	// Build query with filters
 	query := `
-		SELECT id, filename, file_type, status, progress, page_count,
-		       thumbnail_path, error_message, created_at, updated_at, completed_at
+		SELECT id, filename, file_type, status, priority, progress, page_count,
+		       thumbnail_path, error_message, options, attempt, max_attempts, next_attempt_at,
+		       created_at, updated_at, completed_at
 		FROM requests
 	`
 
@@ -90,13 +159,38 @@ func (r *requestRepository) List(ctx context.Context, filter ports.RequestFilter
 	args := []interface{}{}
 	argIndex := 1
 
+	conditions := []string{}
 	if filter.Status != nil {
-		query += fmt.Sprintf(" WHERE status = $%d", argIndex)
-		countQuery += fmt.Sprintf(" WHERE status = $%d", argIndex)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
 		args = append(args, *filter.Status)
 		argIndex++
 	}
 
+	if filter.Priority != nil {
+		conditions = append(conditions, fmt.Sprintf("priority = $%d", argIndex))
+		args = append(args, *filter.Priority)
+		argIndex++
+	}
+
+	if filter.CompletedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("completed_at < $%d", argIndex))
+		args = append(args, *filter.CompletedBefore)
+		argIndex++
+	}
+
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("updated_at < $%d", argIndex))
+		args = append(args, *filter.UpdatedBefore)
+		argIndex++
+	}
+
+	if len(conditions) > 0 {
+		where := " WHERE " + strings.Join(conditions, " AND ")
+		query += where
+		countQuery += where
+	}
+	filterArgs := len(args)
+
 	query += " ORDER BY created_at DESC"
 
 	if filter.Limit > 0 {
@@ -113,11 +207,7 @@ func (r *requestRepository) List(ctx context.Context, filter ports.RequestFilter
 
 	// Get total count
 	var total int
-	countArgs := args[:0]
-	if filter.Status != nil {
-		countArgs = args[:1]
-	}
-	err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
+	err := r.db.QueryRow(ctx, countQuery, args[:filterArgs]...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count requests: %w", err)
 	}
@@ -137,10 +227,15 @@ func (r *requestRepository) List(ctx context.Context, filter ports.RequestFilter
 			&req.Filename,
 			&req.FileType,
 			&req.Status,
+			&req.Priority,
 			&req.Progress,
 			&req.PageCount,
 			&req.ThumbnailPath,
 			&req.ErrorMessage,
+			&req.Options,
+			&req.Attempt,
+			&req.MaxAttempts,
+			&req.NextAttemptAt,
 			&req.CreatedAt,
 			&req.UpdatedAt,
 			&req.CompletedAt,
@@ -158,13 +253,139 @@ func (r *requestRepository) List(ctx context.Context, filter ports.RequestFilter
 	return requests, total, nil
 }
 
+func (r *requestRepository) FindByIdempotencyKey(ctx context.Context, key string, since time.Time) ([]*domain.Request, error) {
+	query := `
+		SELECT id, filename, file_type, status, priority, progress, page_count,
+		       thumbnail_path, error_message, options, attempt, max_attempts, next_attempt_at,
+		       idempotency_key, idempotency_hash, created_at, updated_at, completed_at
+		FROM requests
+		WHERE idempotency_key = $1 AND created_at >= $2
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, key, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find requests by idempotency key: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*domain.Request{}
+	for rows.Next() {
+		var req domain.Request
+		err := rows.Scan(
+			&req.ID,
+			&req.Filename,
+			&req.FileType,
+			&req.Status,
+			&req.Priority,
+			&req.Progress,
+			&req.PageCount,
+			&req.ThumbnailPath,
+			&req.ErrorMessage,
+			&req.Options,
+			&req.Attempt,
+			&req.MaxAttempts,
+			&req.NextAttemptAt,
+			&req.IdempotencyKey,
+			&req.IdempotencyHash,
+			&req.CreatedAt,
+			&req.UpdatedAt,
+			&req.CompletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan request: %w", err)
+		}
+		requests = append(requests, &req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *requestRepository) ReleaseIdempotencyKey(ctx context.Context, key string, before time.Time) error {
+	query := `
+		UPDATE requests
+		SET idempotency_key = NULL, idempotency_hash = NULL, idempotency_seq = NULL
+		WHERE idempotency_key = $1 AND created_at < $2
+	`
+
+	if _, err := r.db.Exec(ctx, query, key, before); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) RequeueWithTask(
+	ctx context.Context,
+	request *domain.Request,
+	from []domain.RequestStatus,
+	task *domain.OutboxTask,
+) error {
+	for _, status := range from {
+		if err := domain.ValidateTransition(status, domain.StatusQueued); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Unlike Update, a request that is already queued does not match, so concurrent
+	// retries cannot both enqueue it
+	query := `
+		UPDATE requests
+		SET status = $1, progress = $2, error_message = $3, options = $4,
+		    updated_at = $5, completed_at = $6, next_attempt_at = $7
+		WHERE id = $8 AND status = ANY($9)
+	`
+
+	result, err := tx.Exec(ctx, query,
+		request.Status,
+		request.Progress,
+		request.ErrorMessage,
+		request.Options,
+		request.UpdatedAt,
+		request.CompletedAt,
+		request.NextAttemptAt,
+		request.ID,
+		statusNames(from),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to requeue request: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, request.ID, domain.StatusQueued)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM results WHERE request_id = $1`, request.ID); err != nil {
+		return fmt.Errorf("failed to delete results: %w", err)
+	}
+	if err := insertOutboxTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (r *requestRepository) Update(ctx context.Context, request *domain.Request) error {
 	query := `
 		UPDATE requests
 		SET filename = $1, file_type = $2, status = $3, progress = $4,
 		    page_count = $5, thumbnail_path = $6, error_message = $7,
-		    updated_at = $8, completed_at = $9
-		WHERE id = $10
+		    options = $8, priority = $9, updated_at = $10, completed_at = $11,
+		    next_attempt_at = $14
+		WHERE id = $12 AND (status = $3 OR status = ANY($13))
 	`
 
 	result, err := r.db.Exec(ctx, query,
@@ -175,9 +396,13 @@ func (r *requestRepository) Update(ctx context.Context, request *domain.Request)
 		request.PageCount,
 		request.ThumbnailPath,
 		request.ErrorMessage,
+		request.Options,
+		request.Priority,
 		request.UpdatedAt,
 		request.CompletedAt,
 		request.ID,
+		statusNames(domain.SourcesOf(request.Status)),
+		request.NextAttemptAt,
 	)
 
 	if err != nil {
@@ -185,27 +410,192 @@ func (r *requestRepository) Update(ctx context.Context, request *domain.Request)
 	}
 
 	if result.RowsAffected() == 0 {
-		return domain.ErrNotFound
+		return r.notTransitioned(ctx, request.ID, request.Status)
 	}
 
 	return nil
 }
 
 func (r *requestRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.RequestStatus, progress int) error {
+	// A late progress update from the worker must not revive a cancelled or finished request
 	query := `
 		UPDATE requests
-		SET status = $1, progress = $2, updated_at = NOW()
-		WHERE id = $3
+		SET status = $1, progress = $2, updated_at = NOW(),
+		    completed_at = CASE WHEN $1 IN ('completed', 'partial', 'failed', 'cancelled') THEN NOW() END
+		WHERE id = $3 AND status = ANY($4)
 	`
 
-	result, err := r.db.Exec(ctx, query, status, progress, id)
+	result, err := r.db.Exec(ctx, query, status, progress, id, statusNames(domain.SourcesOf(status)))
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, id, status)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) TransitionStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	from []domain.RequestStatus,
+	to domain.RequestStatus,
+) error {
+	for _, status := range from {
+		if err := domain.ValidateTransition(status, to); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE requests
+		SET status = $1, updated_at = NOW(), next_attempt_at = NULL,
+		    completed_at = CASE WHEN $1 IN ('completed', 'partial', 'failed', 'cancelled') THEN NOW() END
+		WHERE id = $2 AND status = ANY($3)
+	`
+
+	result, err := r.db.Exec(ctx, query, to, id, statusNames(from))
+	if err != nil {
+		return fmt.Errorf("failed to transition status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, id, to)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) Fail(ctx context.Context, id uuid.UUID, message string) error {
+	query := `
+		UPDATE requests
+		SET status = 'failed', error_message = $2, next_attempt_at = NULL, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1 AND status = ANY($3)
+	`
+
+	result, err := r.db.Exec(ctx, query, id, message, statusNames(domain.SourcesOf(domain.StatusFailed)))
+	if err != nil {
+		return fmt.Errorf("failed to fail request: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, id, domain.StatusFailed)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) StartAttempt(ctx context.Context, id uuid.UUID, attempt, maxAttempts int) error {
+	query := `
+		UPDATE requests
+		SET status = 'processing', progress = 0, attempt = $2, max_attempts = $3,
+		    next_attempt_at = NULL, error_message = NULL, updated_at = NOW(), completed_at = NULL
+		WHERE id = $1 AND status = ANY($4)
+	`
+
+	result, err := r.db.Exec(ctx, query, id, attempt, maxAttempts, statusNames(domain.SourcesOf(domain.StatusProcessing)))
+	if err != nil {
+		return fmt.Errorf("failed to start attempt: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, id, domain.StatusProcessing)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) ScheduleRetry(ctx context.Context, id uuid.UUID, message string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE requests
+		SET status = 'retrying', error_message = $2, next_attempt_at = $3, updated_at = NOW()
+		WHERE id = $1 AND status = ANY($4)
+	`
+
+	result, err := r.db.Exec(ctx, query, id, message, nextAttemptAt, statusNames(domain.SourcesOf(domain.StatusRetrying)))
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notTransitioned(ctx, id, domain.StatusRetrying)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) SetPriority(ctx context.Context, id uuid.UUID, priority domain.Priority) error {
+	// Only a waiting request can still change queue
+	query := `
+		UPDATE requests
+		SET priority = $1, updated_at = NOW()
+		WHERE id = $2 AND status = 'queued'
+	`
+
+	result, err := r.db.Exec(ctx, query, priority, id)
+	if err != nil {
+		return fmt.Errorf("failed to set priority: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notUpdated(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *requestRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM requests WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete request: %w", err)
+	}
+
 	if result.RowsAffected() == 0 {
 		return domain.ErrNotFound
 	}
 
 	return nil
 }
+
+// notUpdated tells apart a missing request from one whose status prevented a conditional update
+func (r *requestRepository) notUpdated(ctx context.Context, id uuid.UUID) error {
+	status, err := r.currentStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("request is %s: %w", status, domain.ErrInvalidState)
+}
+
+// notTransitioned tells apart a missing request from one that cannot move to status
+func (r *requestRepository) notTransitioned(ctx context.Context, id uuid.UUID, to domain.RequestStatus) error {
+	status, err := r.currentStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	return &domain.TransitionError{From: status, To: to}
+}
+
+func (r *requestRepository) currentStatus(ctx context.Context, id uuid.UUID) (domain.RequestStatus, error) {
+	var status domain.RequestStatus
+	err := r.db.QueryRow(ctx, `SELECT status FROM requests WHERE id = $1`, id).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get request status: %w", err)
+	}
+	return status, nil
+}
+
+// statusNames converts statuses to a text array parameter
+func statusNames(statuses []domain.RequestStatus) []string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return names
+}
@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to the database in DATABASE_URL and migrates a schema of its own,
+// dropped when the test ends. The test is skipped when DATABASE_URL is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	admin, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if pool, err := pgxpool.New(ctx, url); err == nil {
+			pool.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+			pool.Close()
+		}
+	})
+
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrations, err := filepath.Glob("../../../../migrations/*.up.sql")
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	for _, migration := range migrations {
+		sql, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Exec(ctx, string(sql)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(migration), err)
+		}
+	}
+	return pool
+}
+
+// storedRequest saves a new request and forces its status
+func storedRequest(t *testing.T, repo *requestRepository, status domain.RequestStatus) *domain.Request {
+	ctx := context.Background()
+	request := domain.NewRequest("page.png", domain.FileTypeImage)
+	if err := repo.Create(ctx, request); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.db.Exec(ctx, `UPDATE requests SET status = $2 WHERE id = $1`, request.ID, status); err != nil {
+		t.Fatal(err)
+	}
+	request.Status = status
+	return request
+}
+
+func TestRequestStatusUpdates(t *testing.T) {
+	repo := &requestRepository{db: testPool(t)}
+	ctx := context.Background()
+
+	update := func(to domain.RequestStatus) func(*domain.Request) error {
+		return func(request *domain.Request) error {
+			request.Status = to
+			return repo.Update(ctx, request)
+		}
+	}
+	updateStatus := func(to domain.RequestStatus) func(*domain.Request) error {
+		return func(request *domain.Request) error {
+			return repo.UpdateStatus(ctx, request.ID, to, 50)
+		}
+	}
+	transition := func(from []domain.RequestStatus, to domain.RequestStatus) func(*domain.Request) error {
+		return func(request *domain.Request) error {
+			return repo.TransitionStatus(ctx, request.ID, from, to)
+		}
+	}
+	fail := func(request *domain.Request) error {
+		return repo.Fail(ctx, request.ID, "boom")
+	}
+	startAttempt := func(request *domain.Request) error {
+		return repo.StartAttempt(ctx, request.ID, 2, 4)
+	}
+	scheduleRetry := func(request *domain.Request) error {
+		return repo.ScheduleRetry(ctx, request.ID, "boom", time.Now().Add(time.Minute))
+	}
+
+	tests := []struct {
+		name       string
+		stored     domain.RequestStatus
+		op         func(*domain.Request) error
+		wantStatus domain.RequestStatus // status stored afterwards; the stored one on ErrInvalidState
+		wantErr    error
+	}{
+		{"update queued to processing", domain.StatusQueued, update(domain.StatusProcessing), domain.StatusProcessing, nil},
+		{"update keeps its status", domain.StatusFailed, update(domain.StatusFailed), domain.StatusFailed, nil},
+		{"update retries a completed request", domain.StatusCompleted, update(domain.StatusQueued), domain.StatusQueued, nil},
+		{"update revives a cancelled request", domain.StatusCancelled, update(domain.StatusProcessing), domain.StatusCancelled, domain.ErrInvalidState},
+		{"update completes a queued request", domain.StatusQueued, update(domain.StatusCompleted), domain.StatusQueued, domain.ErrInvalidState},
+
+		{"progress of a processing request", domain.StatusProcessing, updateStatus(domain.StatusProcessing), domain.StatusProcessing, nil},
+		{"progress of a cancelled request", domain.StatusCancelled, updateStatus(domain.StatusProcessing), domain.StatusCancelled, domain.ErrInvalidState},
+		{"completion of a failed request", domain.StatusFailed, updateStatus(domain.StatusCompleted), domain.StatusFailed, domain.ErrInvalidState},
+
+		{"transition from the stored status", domain.StatusQueued,
+			transition([]domain.RequestStatus{domain.StatusQueued}, domain.StatusCancelled), domain.StatusCancelled, nil},
+		{"transition from another status", domain.StatusProcessing,
+			transition([]domain.RequestStatus{domain.StatusQueued}, domain.StatusCancelled), domain.StatusProcessing, domain.ErrInvalidState},
+		{"transition that is never allowed", domain.StatusCompleted,
+			transition([]domain.RequestStatus{domain.StatusCompleted}, domain.StatusProcessing), domain.StatusCompleted, domain.ErrInvalidState},
+
+		{"fail a processing request", domain.StatusProcessing, fail, domain.StatusFailed, nil},
+		{"fail a completed request", domain.StatusCompleted, fail, domain.StatusCompleted, domain.ErrInvalidState},
+		{"fail a cancelled request", domain.StatusCancelled, fail, domain.StatusCancelled, domain.ErrInvalidState},
+
+		{"start a queued request", domain.StatusQueued, startAttempt, domain.StatusProcessing, nil},
+		{"start the next attempt", domain.StatusRetrying, startAttempt, domain.StatusProcessing, nil},
+		{"start a cancelled request", domain.StatusCancelled, startAttempt, domain.StatusCancelled, domain.ErrInvalidState},
+		{"start a completed request", domain.StatusCompleted, startAttempt, domain.StatusCompleted, domain.ErrInvalidState},
+
+		{"retry a processing request", domain.StatusProcessing, scheduleRetry, domain.StatusRetrying, nil},
+		{"retry a queued request", domain.StatusQueued, scheduleRetry, domain.StatusQueued, domain.ErrInvalidState},
+		{"retry a cancelled request", domain.StatusCancelled, scheduleRetry, domain.StatusCancelled, domain.ErrInvalidState},
+	}
+
+	for _, tt := range tests {
+		request := storedRequest(t, repo, tt.stored)
+
+		err := tt.op(request)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+
+		status, err := repo.currentStatus(ctx, request.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != tt.wantStatus {
+			t.Errorf("%s: got status %s, want %s", tt.name, status, tt.wantStatus)
+		}
+	}
+}
+
+func TestRequestStatusUpdatesOfMissingRequest(t *testing.T) {
+	repo := &requestRepository{db: testPool(t)}
+	ctx := context.Background()
+	id := uuid.New()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"update status", repo.UpdateStatus(ctx, id, domain.StatusProcessing, 10)},
+		{"fail", repo.Fail(ctx, id, "boom")},
+		{"start attempt", repo.StartAttempt(ctx, id, 1, 4)},
+		{"schedule retry", repo.ScheduleRetry(ctx, id, "boom", time.Now())},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, domain.ErrNotFound) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.err, domain.ErrNotFound)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,8 +23,8 @@ func NewResultRepository(db *pgxpool.Pool) ports.ResultRepository {
 
 func (r *resultRepository) Create(ctx context.Context, result *domain.Result) error {
 	query := `
-		INSERT INTO results (id, request_id, page_number, original_path, translated_path, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO results (id, request_id, page_number, original_path, translated_path, missing, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	_, err := r.db.Exec(ctx, query,
@@ -31,6 +33,7 @@ func (r *resultRepository) Create(ctx context.Context, result *domain.Result) er
 		result.PageNumber,
 		result.OriginalPath,
 		result.TranslatedPath,
+		result.Missing,
 		result.CreatedAt,
 	)
 
@@ -53,8 +56,8 @@ func (r *resultRepository) CreateBatch(ctx context.Context, results []*domain.Re
 	defer tx.Rollback(ctx)
 
 	query := `
-		INSERT INTO results (id, request_id, page_number, original_path, translated_path, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO results (id, request_id, page_number, original_path, translated_path, missing, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	for _, result := range results {
@@ -64,6 +67,7 @@ func (r *resultRepository) CreateBatch(ctx context.Context, results []*domain.Re
 			result.PageNumber,
 			result.OriginalPath,
 			result.TranslatedPath,
+			result.Missing,
 			result.CreatedAt,
 		)
 		if err != nil {
@@ -80,7 +84,7 @@ func (r *resultRepository) CreateBatch(ctx context.Context, results []*domain.Re
 
 func (r *resultRepository) GetByRequestID(ctx context.Context, requestID uuid.UUID) ([]*domain.Result, error) {
 	query := `
-		SELECT id, request_id, page_number, original_path, translated_path, created_at
+		SELECT id, request_id, page_number, original_path, translated_path, missing, created_at
 		FROM results
 		WHERE request_id = $1
 		ORDER BY page_number ASC
@@ -101,6 +105,7 @@ func (r *resultRepository) GetByRequestID(ctx context.Context, requestID uuid.UU
 			&result.PageNumber,
 			&result.OriginalPath,
 			&result.TranslatedPath,
+			&result.Missing,
 			&result.CreatedAt,
 		)
 		if err != nil {
@@ -116,6 +121,59 @@ func (r *resultRepository) GetByRequestID(ctx context.Context, requestID uuid.UU
 	return results, nil
 }
 
+func (r *resultRepository) GetByPage(ctx context.Context, requestID uuid.UUID, pageNumber int) (*domain.Result, error) {
+	query := `
+		SELECT id, request_id, page_number, original_path, translated_path, missing, created_at
+		FROM results
+		WHERE request_id = $1 AND page_number = $2
+	`
+
+	var result domain.Result
+	err := r.db.QueryRow(ctx, query, requestID, pageNumber).Scan(
+		&result.ID,
+		&result.RequestID,
+		&result.PageNumber,
+		&result.OriginalPath,
+		&result.TranslatedPath,
+		&result.Missing,
+		&result.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get result: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (r *resultRepository) Update(ctx context.Context, result *domain.Result) error {
+	query := `
+		UPDATE results
+		SET original_path = $1, translated_path = $2, missing = $3
+		WHERE id = $4
+	`
+
+	tag, err := r.db.Exec(ctx, query,
+		result.OriginalPath,
+		result.TranslatedPath,
+		result.Missing,
+		result.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update result: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *resultRepository) DeleteByRequestID(ctx context.Context, requestID uuid.UUID) error {
 	query := `DELETE FROM results WHERE request_id = $1`
 
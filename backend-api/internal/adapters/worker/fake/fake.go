@@ -0,0 +1,292 @@
+// Package fake implements a worker executor that needs neither Python nor a GPU.
+// It stamps every page instead of translating it and reports progress like the real
+// worker, so the upload, queue, SSE and results flow can run on a laptop or in CI.
+package fake
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/pkg/natsort"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp"
+)
+
+// stampText is written across the top of every page
+const stampText = "FAKE TRANSLATION"
+
+// imageExts lists the page formats the fake worker picks up in an archive, like the real one
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".bmp":  true,
+}
+
+// executor fakes the Python worker
+type executor struct {
+	cfg    config.FakeWorkerConfig
+	logger *zap.Logger
+}
+
+// NewExecutor creates a worker executor that stamps pages instead of translating them,
+// with the latency and failure rates of cfg.Fake
+func NewExecutor(cfg *config.WorkerConfig, logger *zap.Logger) ports.WorkerExecutor {
+	return &executor{cfg: cfg.Fake, logger: logger}
+}
+
+// job is one run of the fake worker
+type job struct {
+	cfg        config.FakeWorkerConfig
+	onProgress ports.ProgressCallback
+	onPage     ports.PageCallback
+	failAt     int // page at which the job fails, 0 if it does not
+}
+
+func (e *executor) Translate(
+	ctx context.Context,
+	requestID uuid.UUID,
+	inputPath string,
+	options domain.TranslationOptions,
+	onProgress ports.ProgressCallback,
+	onPage ports.PageCallback,
+) (*ports.TranslationOutput, error) {
+	e.logger.Info("starting fake translation",
+		zap.String("request_id", requestID.String()),
+		zap.String("input_path", inputPath),
+		zap.Any("options", options),
+	)
+
+	j := &job{cfg: e.cfg, onProgress: onProgress, onPage: onPage}
+	j.progress(0, "Initializing AI worker")
+	j.progress(5, "AI pipeline ready")
+
+	var output *ports.TranslationOutput
+	var err error
+	if strings.EqualFold(filepath.Ext(inputPath), ".zip") {
+		output, err = j.translateZip(ctx, inputPath)
+	} else {
+		output, err = j.translateImage(ctx, inputPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.Info("fake translation completed", zap.String("output_path", output.OutputPath))
+	return output, nil
+}
+
+func (j *job) translateZip(ctx context.Context, inputPath string) (*ports.TranslationOutput, error) {
+	reader, err := zip.OpenReader(inputPath)
+	if err != nil {
+		return nil, &domain.WorkerError{Code: domain.WorkerCodeInvalidInput, Message: "Invalid ZIP: " + err.Error()}
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File)
+	var names []string
+	for _, f := range reader.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if imageExts[strings.ToLower(path.Ext(f.Name))] {
+			files[f.Name] = f
+			names = append(names, f.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, &domain.WorkerError{Code: domain.WorkerCodeInvalidInput, Message: "No images found in ZIP"}
+	}
+	natsort.Sort(names)
+	j.pickFailure(len(names))
+
+	base := filepath.Base(inputPath)
+	outputPath := filepath.Join(filepath.Dir(inputPath), strings.TrimSuffix(base, filepath.Ext(base))+"_translated.zip")
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translated zip: %w", err)
+	}
+	archive := zip.NewWriter(out)
+
+	for i, name := range names {
+		_, err := j.page(ctx, i+1, len(names), name, func() (io.ReadCloser, error) { return files[name].Open() },
+			func() (io.Writer, error) { return archive.Create(strings.TrimSuffix(name, path.Ext(name)) + ".jpg") })
+		if err != nil {
+			out.Close()
+			os.Remove(outputPath)
+			return nil, err
+		}
+		j.progress(5+90*(i+1)/len(names), fmt.Sprintf("Translated %d/%d pages", i+1, len(names)))
+	}
+
+	j.progress(95, "Packaging translated pages")
+	if err := archive.Close(); err != nil {
+		out.Close()
+		os.Remove(outputPath)
+		return nil, fmt.Errorf("failed to write translated zip: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(outputPath)
+		return nil, fmt.Errorf("failed to write translated zip: %w", err)
+	}
+	j.progress(100, "Translation completed: "+filepath.Base(outputPath))
+
+	return &ports.TranslationOutput{OutputPath: outputPath, Pages: []ports.PageOutput{}}, nil
+}
+
+func (j *job) translateImage(ctx context.Context, inputPath string) (*ports.TranslationOutput, error) {
+	base := filepath.Base(inputPath)
+	outputPath := filepath.Join(filepath.Dir(inputPath), "translated_"+strings.TrimSuffix(base, filepath.Ext(base))+".jpg")
+	j.pickFailure(1)
+
+	var out *os.File
+	status, err := j.page(ctx, 1, 1, base, func() (io.ReadCloser, error) { return os.Open(inputPath) },
+		func() (io.Writer, error) {
+			var err error
+			out, err = os.Create(outputPath)
+			return out, err
+		})
+	if out != nil {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return nil, err
+	}
+	// Without other pages, a page that was not translated fails the job, as it does with the real worker
+	switch status {
+	case domain.PageStatusSkipped:
+		return nil, &domain.WorkerError{Code: domain.WorkerCodeInvalidInput, Message: "Cannot read image: " + base}
+	case domain.PageStatusFailed:
+		return nil, &domain.WorkerError{Code: domain.WorkerCodeInternal, Message: "Simulated page failure: " + base}
+	}
+	j.progress(100, "Translation completed: "+filepath.Base(outputPath))
+
+	return &ports.TranslationOutput{
+		OutputPath: outputPath,
+		Pages: []ports.PageOutput{
+			{PageNumber: 1, OriginalPath: inputPath, TranslatedPath: outputPath},
+		},
+	}, nil
+}
+
+// page "translates" one page: it waits for the configured delay, then stamps the page and
+// writes it as JPEG. A page that fails or cannot be decoded is reported and not written.
+// It returns the final status of the page; an error stops the whole job.
+func (j *job) page(
+	ctx context.Context,
+	index, total int,
+	name string,
+	open func() (io.ReadCloser, error),
+	create func() (io.Writer, error),
+) (domain.PageStatus, error) {
+	j.reportPage(name, domain.PageStatusProcessing, "")
+
+	timer := time.NewTimer(j.cfg.PageDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-timer.C:
+	}
+
+	if index == j.failAt {
+		return "", &domain.WorkerError{Code: domain.WorkerCodeInternal, Message: fmt.Sprintf("Simulated worker failure on page %d of %d", index, total)}
+	}
+	if j.cfg.PageFailureRate > 0 && rand.Float64() < j.cfg.PageFailureRate {
+		j.reportPage(name, domain.PageStatusFailed, "Simulated page failure")
+		return domain.PageStatusFailed, nil
+	}
+
+	img, err := decode(open)
+	if err != nil {
+		j.reportPage(name, domain.PageStatusSkipped, "Cannot read image: "+err.Error())
+		return domain.PageStatusSkipped, nil
+	}
+
+	w, err := create()
+	if err != nil {
+		return "", fmt.Errorf("failed to write translated page: %w", err)
+	}
+	if err := jpeg.Encode(w, stamp(img), &jpeg.Options{Quality: 90}); err != nil {
+		return "", fmt.Errorf("failed to encode translated page: %w", err)
+	}
+	j.reportPage(name, domain.PageStatusDone, "")
+	return domain.PageStatusDone, nil
+}
+
+// pickFailure decides up front whether, and on which page, the job fails
+func (j *job) pickFailure(total int) {
+	if j.cfg.FailureRate > 0 && rand.Float64() < j.cfg.FailureRate {
+		j.failAt = rand.IntN(total) + 1
+	}
+}
+
+func (j *job) progress(percent int, message string) {
+	if j.onProgress != nil {
+		j.onProgress(percent, message)
+	}
+}
+
+func (j *job) reportPage(name string, status domain.PageStatus, message string) {
+	if j.onPage != nil {
+		j.onPage(ports.PageUpdate{Name: name, Status: status, Error: message})
+	}
+}
+
+func decode(open func() (io.ReadCloser, error)) (image.Image, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	img, _, err := image.Decode(bufio.NewReader(rc))
+	return img, err
+}
+
+// stamp copies img with a banner across its top, so a fake translation is easy to spot
+func stamp(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+
+	face := basicfont.Face7x13
+	height := min(out.Bounds().Dy(), face.Height+8)
+	banner := image.Rect(0, 0, out.Bounds().Dx(), height)
+	draw.Draw(out, banner, image.NewUniform(color.RGBA{R: 200, G: 30, B: 30, A: 255}), image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{
+		Dst:  out,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(4, 4+face.Ascent),
+	}
+	drawer.DrawString(stampText)
+	return out
+}
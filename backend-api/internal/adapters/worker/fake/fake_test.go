@@ -0,0 +1,180 @@
+package fake
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/testutil"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recorder collects what an executor reports
+type recorder struct {
+	pages    map[string]domain.PageStatus
+	progress []int
+}
+
+func (r *recorder) translate(executor ports.WorkerExecutor, ctx context.Context, inputPath string) (*ports.TranslationOutput, error) {
+	r.pages = make(map[string]domain.PageStatus)
+	return executor.Translate(ctx, uuid.New(), inputPath, domain.TranslationOptions{},
+		func(percent int, _ string) { r.progress = append(r.progress, percent) },
+		func(update ports.PageUpdate) { r.pages[update.Name] = update.Status })
+}
+
+func newExecutor(cfg config.FakeWorkerConfig) ports.WorkerExecutor {
+	return NewExecutor(&config.WorkerConfig{Fake: cfg}, zap.NewNop())
+}
+
+func TestTranslateZip(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.FakeWorkerConfig
+		wantPages map[string]domain.PageStatus
+		wantFiles []string
+	}{
+		{
+			name: "all pages",
+			wantPages: map[string]domain.PageStatus{
+				"ch1/001.png": domain.PageStatusDone,
+				"ch1/002.png": domain.PageStatusDone,
+				"broken.png":  domain.PageStatusSkipped,
+			},
+			wantFiles: []string{"ch1/001.jpg", "ch1/002.jpg"},
+		},
+		{
+			name: "failing pages",
+			cfg:  config.FakeWorkerConfig{PageFailureRate: 1},
+			wantPages: map[string]domain.PageStatus{
+				"ch1/001.png": domain.PageStatusFailed,
+				"ch1/002.png": domain.PageStatusFailed,
+				"broken.png":  domain.PageStatusFailed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		input := filepath.Join(dir, "chapter.zip")
+		testutil.WriteZip(t, input, map[string][]byte{
+			"ch1/001.png": testutil.PNGPage(t),
+			"ch1/002.png": testutil.PNGPage(t),
+			"broken.png":  []byte("not an image"),
+			"notes.txt":   []byte("ignored"),
+		})
+
+		var r recorder
+		output, err := r.translate(newExecutor(tt.cfg), context.Background(), input)
+		if err != nil {
+			t.Errorf("%s: Translate: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.pages, tt.wantPages) {
+			t.Errorf("%s: pages: got %v, want %v", tt.name, r.pages, tt.wantPages)
+		}
+		if last := r.progress[len(r.progress)-1]; last != 100 {
+			t.Errorf("%s: final progress: got %d, want 100", tt.name, last)
+		}
+
+		archive, err := zip.OpenReader(output.OutputPath)
+		if err != nil {
+			t.Errorf("%s: open translated zip: %v", tt.name, err)
+			continue
+		}
+		var files []string
+		for _, f := range archive.File {
+			files = append(files, f.Name)
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := jpeg.Decode(rc)
+			rc.Close()
+			if err != nil {
+				t.Errorf("%s: %s is not a JPEG: %v", tt.name, f.Name, err)
+				continue
+			}
+			// The banner covers the top of the white page
+			if got := color.RGBAModel.Convert(img.At(0, 0)).(color.RGBA); got.G > 100 {
+				t.Errorf("%s: %s is not stamped: top-left pixel %v", tt.name, f.Name, got)
+			}
+		}
+		archive.Close()
+		if !reflect.DeepEqual(files, tt.wantFiles) {
+			t.Errorf("%s: translated files: got %v, want %v", tt.name, files, tt.wantFiles)
+		}
+	}
+}
+
+func TestTranslateImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		cfg      config.FakeWorkerConfig
+		wantCode string
+	}{
+		{name: "image", content: testutil.PNGPage(t)},
+		{name: "unreadable image", content: []byte("not an image"), wantCode: domain.WorkerCodeInvalidInput},
+		{name: "failing job", content: testutil.PNGPage(t), cfg: config.FakeWorkerConfig{FailureRate: 1}, wantCode: domain.WorkerCodeInternal},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		input := filepath.Join(dir, "cover.png")
+		if err := os.WriteFile(input, tt.content, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var r recorder
+		output, err := r.translate(newExecutor(tt.cfg), context.Background(), input)
+		wantPath := filepath.Join(dir, "translated_cover.jpg")
+		if tt.wantCode != "" {
+			var workerErr *domain.WorkerError
+			if !errors.As(err, &workerErr) || workerErr.Code != tt.wantCode {
+				t.Errorf("%s: got %v, want worker error code %q", tt.name, err, tt.wantCode)
+			}
+			if _, err := os.Stat(wantPath); !os.IsNotExist(err) {
+				t.Errorf("%s: output of a failed job was kept", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Translate: %v", tt.name, err)
+			continue
+		}
+		if output.OutputPath != wantPath || len(output.Pages) != 1 || output.Pages[0].TranslatedPath != wantPath {
+			t.Errorf("%s: output: got %+v, want one page at %s", tt.name, output, wantPath)
+		}
+		if r.pages["cover.png"] != domain.PageStatusDone {
+			t.Errorf("%s: page status: got %q, want %q", tt.name, r.pages["cover.png"], domain.PageStatusDone)
+		}
+	}
+}
+
+func TestTranslateCancel(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "chapter.zip")
+	testutil.WriteZip(t, input, map[string][]byte{"001.png": testutil.PNGPage(t), "002.png": testutil.PNGPage(t)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var r recorder
+	_, err := r.translate(newExecutor(config.FakeWorkerConfig{PageDelay: time.Hour}), ctx, input)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chapter_translated.zip")); !os.IsNotExist(err) {
+		t.Errorf("partial output was not removed")
+	}
+}
@@ -0,0 +1,144 @@
+// Package protocol reads the event stream of the AI worker, shared by the local executors
+// and the remote one.
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"go.uber.org/zap"
+)
+
+// EventPrefix marks a structured event among the worker's stdout lines
+const EventPrefix = "EVENT: "
+
+// Version is the newest event protocol version the executors understand
+const Version = 1
+
+// Event types sent by the worker
+const (
+	EventStageStarted  = "stage_started"
+	EventStageFinished = "stage_finished"
+	EventPage          = "page"
+	EventProgress      = "progress"
+	EventWarning       = "warning"
+	EventError         = "error"
+	EventArtifact      = "artifact"
+
+	// Sent by daemon.py and the remote worker service
+	EventReady       = "ready"
+	EventPong        = "pong"
+	EventJobFinished = "job_finished"
+	EventFile        = "file" // remote only
+)
+
+// Worker stages
+const (
+	StageInit      = "init"
+	StageTranslate = "translate"
+	StagePackage   = "package"
+)
+
+// Event is one line of the worker's event stream: EVENT: {"v":1,"type":"page",...}.
+// Only the fields of its type are set.
+type Event struct {
+	Version int    `json:"v"`
+	Type    string `json:"type"`
+	Stage   string `json:"stage,omitempty"`   // stage_started, stage_finished
+	Name    string `json:"name,omitempty"`    // page, file: slash-separated path inside the input
+	Status  string `json:"status,omitempty"`  // page, job_finished
+	Index   int    `json:"index,omitempty"`   // page: 1-based position, 0 if unknown
+	Total   int    `json:"total,omitempty"`   // page, stage_started: page count, 0 if unknown
+	Error   string `json:"error,omitempty"`   // page: why it was skipped or failed
+	Percent int    `json:"percent,omitempty"` // progress
+	Message string `json:"message,omitempty"` // progress, warning, error
+	Code    string `json:"code,omitempty"`    // error
+	Path    string `json:"path,omitempty"`    // artifact
+	Kind    string `json:"kind,omitempty"`    // artifact: archive or image
+	Job     string `json:"job,omitempty"`     // daemon: job the event belongs to
+	RSS     int64  `json:"rss,omitempty"`     // pong: resident memory in bytes
+	Jobs    int    `json:"jobs,omitempty"`    // pong: jobs run so far
+	Data    []byte `json:"data,omitempty"`    // file: content, base64 in JSON
+}
+
+// ParseLine parses an event line. ok is false for any other line;
+// err is set for an event line that is malformed or from a newer protocol.
+func ParseLine(line string) (event Event, ok bool, err error) {
+	payload, found := strings.CutPrefix(line, EventPrefix)
+	if !found {
+		return Event{}, false, nil
+	}
+	event, err = Parse([]byte(payload))
+	return event, true, err
+}
+
+// Parse parses the JSON of one event
+func Parse(payload []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("malformed worker event: %w", err)
+	}
+	if event.Version < 1 || event.Version > Version {
+		return Event{}, fmt.Errorf("unsupported worker event version %d", event.Version)
+	}
+	if event.Type == "" {
+		return Event{}, fmt.Errorf("worker event without type")
+	}
+	return event, nil
+}
+
+// Run collects what the worker reported during one run
+type Run struct {
+	Structured bool                // the worker sends events, other lines are only logged
+	Artifact   string              // output file reported by the worker
+	Err        *domain.WorkerError // error reported before the worker stopped
+}
+
+// Handle applies one event of the worker's event stream
+func Handle(logger *zap.Logger, event Event, run *Run, onProgress ports.ProgressCallback, onPage ports.PageCallback) {
+	progress := func(percent int, message string) {
+		if onProgress != nil {
+			onProgress(percent, message)
+		}
+	}
+
+	switch event.Type {
+	case EventStageStarted:
+		switch event.Stage {
+		case StageInit:
+			progress(0, "Initializing AI worker")
+		case StagePackage:
+			progress(95, "Packaging translated pages")
+		}
+	case EventStageFinished:
+		if event.Stage == StageInit {
+			progress(5, "AI pipeline ready")
+		}
+	case EventPage:
+		status := domain.PageStatus(event.Status)
+		if status != domain.PageStatusProcessing && !status.IsFinal() {
+			logger.Warn("worker reported an unknown page status", zap.String("status", event.Status))
+			return
+		}
+		if onPage != nil {
+			onPage(ports.PageUpdate{Name: event.Name, Status: status, Error: event.Error})
+		}
+	case EventProgress:
+		progress(event.Percent, event.Message)
+	case EventWarning:
+		logger.Warn("worker warning", zap.String("message", event.Message))
+	case EventError:
+		run.Err = &domain.WorkerError{Code: event.Code, Message: event.Message}
+		logger.Error("worker error", zap.String("code", event.Code), zap.String("message", event.Message))
+	case EventArtifact:
+		run.Artifact = event.Path
+		progress(100, "Translation completed: "+filepath.Base(event.Path))
+	default:
+		// Newer workers may send events this executor does not know yet
+		logger.Debug("ignoring unknown worker event", zap.String("type", event.Type))
+	}
+}
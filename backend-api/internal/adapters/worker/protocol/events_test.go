@@ -0,0 +1,54 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEventLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Event
+		isEvent bool
+		wantErr bool
+	}{
+		{
+			name:    "page",
+			line:    `EVENT: {"v": 1, "type": "page", "status": "failed", "name": "ch1/003.png", "index": 3, "total": 40, "error": "CUDA out of memory"}`,
+			want:    Event{Version: 1, Type: EventPage, Status: "failed", Name: "ch1/003.png", Index: 3, Total: 40, Error: "CUDA out of memory"},
+			isEvent: true,
+		},
+		{
+			name:    "error",
+			line:    `EVENT: {"v": 1, "type": "error", "code": "invalid_input", "message": "Invalid ZIP: File is not a zip file"}`,
+			want:    Event{Version: 1, Type: EventError, Code: "invalid_input", Message: "Invalid ZIP: File is not a zip file"},
+			isEvent: true,
+		},
+		{
+			name:    "escaped text",
+			line:    `EVENT: {"v": 1, "type": "warning", "message": "échec"}`,
+			want:    Event{Version: 1, Type: EventWarning, Message: "échec"},
+			isEvent: true,
+		},
+		{name: "legacy progress", line: "PROGRESS: 50% - Translated 5/10 pages"},
+		{name: "human output", line: "✅ Pipeline Ready (V10 - Stable | Masked Inpainting)."},
+		{name: "malformed", line: `EVENT: {"v": 1, "type": `, isEvent: true, wantErr: true},
+		{name: "newer protocol", line: `EVENT: {"v": 2, "type": "page"}`, isEvent: true, wantErr: true},
+		{name: "missing version", line: `EVENT: {"type": "page"}`, isEvent: true, wantErr: true},
+		{name: "missing type", line: `EVENT: {"v": 1}`, isEvent: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, isEvent, err := ParseLine(tt.line)
+		if isEvent != tt.isEvent {
+			t.Errorf("%s: isEvent = %v, want %v", tt.name, isEvent, tt.isEvent)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package python
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/protocol"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// missedPings is how many health checks a daemon may leave unanswered before it is restarted
+const missedPings = 3
+
+// daemonStopTimeout is how long a daemon asked to shut down may take before it is killed
+const daemonStopTimeout = 10 * time.Second
+
+// daemonCommand is one line sent to daemon.py on stdin
+type daemonCommand struct {
+	Op        string         `json:"op"`
+	Job       string         `json:"job,omitempty"`
+	Input     string         `json:"input,omitempty"`
+	Options   *daemonOptions `json:"options,omitempty"`
+	ResumeDir string         `json:"resume_dir,omitempty"`
+	TempDir   string         `json:"temp_dir,omitempty"`
+}
+
+// daemonOptions are the translation options of a job; unset ones keep the worker defaults
+type daemonOptions struct {
+	SourceLang string  `json:"source_lang,omitempty"`
+	TargetLang string  `json:"target_lang,omitempty"`
+	Font       string  `json:"font,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Quality    int     `json:"quality,omitempty"`
+}
+
+// daemonExecutor runs jobs on warm daemon.py processes that keep the models loaded.
+// It holds one daemon per concurrent job; a daemon is replaced when it crashes, stops
+// answering health checks, or reaches its job or memory limit.
+type daemonExecutor struct {
+	base           *pythonExecutor
+	logger         *zap.Logger
+	maxJobs        int
+	maxMemory      int64
+	healthInterval time.Duration
+	cancelGrace    time.Duration
+	limits         config.ResourceLimits
+
+	// slots holds the daemons not running a job; nil for a daemon that could not be started
+	slots chan *daemonProcess
+}
+
+// NewDaemonExecutor creates a worker executor backed by warm Python daemons.
+// The daemons start loading their models right away.
+func NewDaemonExecutor(cfg *config.WorkerConfig, storageCfg *config.StorageConfig, logger *zap.Logger) ports.WorkerExecutor {
+	size := cfg.Concurrency
+	if size < 1 {
+		size = 1
+	}
+
+	// The CPU time of a daemon adds up over every job it runs, so a CPU time limit would end
+	// a healthy daemon after some number of jobs and fail the one it was running
+	limits := cfg.Limits
+	if limits.CPUTime > 0 {
+		logger.Warn("the worker CPU time limit is not applied to daemons, ignoring it")
+		limits.CPUTime = 0
+	}
+
+	e := &daemonExecutor{
+		base:           newPythonExecutor(cfg, storageCfg, logger),
+		logger:         logger,
+		maxJobs:        cfg.Daemon.MaxJobs,
+		maxMemory:      cfg.Daemon.MaxMemoryMB << 20,
+		healthInterval: cfg.Daemon.HealthInterval,
+		cancelGrace:    cfg.Daemon.CancelGrace,
+		limits:         limits,
+		slots:          make(chan *daemonProcess, size),
+	}
+	for i := 0; i < size; i++ {
+		p, err := e.spawn()
+		if err != nil {
+			logger.Error("failed to start worker daemon", zap.Error(err))
+		}
+		e.slots <- p
+	}
+	return e
+}
+
+func (e *daemonExecutor) Translate(
+	ctx context.Context,
+	requestID uuid.UUID,
+	inputPath string,
+	options domain.TranslationOptions,
+	onProgress ports.ProgressCallback,
+	onPage ports.PageCallback,
+) (*ports.TranslationOutput, error) {
+	job, err := e.base.prepareJob(requestID, inputPath, options)
+	if err != nil {
+		return nil, err
+	}
+	defer job.cleanup()
+
+	if onProgress != nil {
+		onProgress(0, "Waiting for AI worker")
+	}
+	p, err := e.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer e.release(p)
+
+	jobID := uuid.New().String()
+	command := daemonCommand{
+		Op:    "translate",
+		Job:   jobID,
+		Input: job.inputPath,
+		Options: &daemonOptions{
+			SourceLang: options.SourceLang,
+			TargetLang: options.TargetLang,
+			Font:       options.Font,
+			Confidence: options.DetectionConfidence,
+			Quality:    options.OutputQuality,
+		},
+		ResumeDir: job.resumeDir,
+		TempDir:   job.tempDir,
+	}
+	if err := p.send(command); err != nil {
+		p.kill()
+		return nil, fmt.Errorf("failed to send job to worker daemon: %w", err)
+	}
+	p.jobs++
+	if onProgress != nil {
+		onProgress(5, "AI pipeline ready")
+	}
+
+	run := &protocol.Run{Structured: true}
+	done := ctx.Done()
+	var grace <-chan time.Time
+	for {
+		select {
+		case event, ok := <-p.events:
+			if !ok {
+				<-p.exited
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				if run.Err != nil {
+					return nil, fmt.Errorf("worker daemon exited: %w", run.Err)
+				}
+				if limitErr := p.tree.limitError(); limitErr != nil {
+					return nil, fmt.Errorf("worker daemon exited: %w", limitErr)
+				}
+				return nil, fmt.Errorf("worker daemon exited: %v", p.exitErr)
+			}
+			if event.Job != jobID {
+				e.logger.Debug("ignoring event of another job", zap.String("job", event.Job), zap.String("type", event.Type))
+				continue
+			}
+			if event.Type != protocol.EventJobFinished {
+				protocol.Handle(e.logger, event, run, onProgress, onPage)
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			switch {
+			case event.Status == "done":
+				output, err := e.base.findOutputFiles(job.inputPath, run.Artifact)
+				if err != nil {
+					return nil, fmt.Errorf("failed to find output files: %w", err)
+				}
+				e.logger.Info("translation completed",
+					zap.String("input_path", job.inputPath),
+					zap.Int("pages", len(output.Pages)),
+				)
+				return output, nil
+			case run.Err != nil:
+				return nil, fmt.Errorf("worker job failed: %w", run.Err)
+			default:
+				return nil, fmt.Errorf("worker job %s", event.Status)
+			}
+
+		case <-done:
+			// Stop only this job, the daemon stays warm for the next one
+			e.logger.Info("cancelling worker job",
+				zap.String("request_id", requestID.String()),
+				zap.String("job", jobID),
+			)
+			if err := p.send(daemonCommand{Op: "cancel", Job: jobID}); err != nil {
+				p.kill()
+				return nil, ctx.Err()
+			}
+			done = nil
+			grace = time.After(e.cancelGrace)
+
+		case <-grace:
+			e.logger.Warn("worker job ignored cancellation, killing its daemon",
+				zap.String("request_id", requestID.String()),
+				zap.String("job", jobID),
+			)
+			p.kill()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close shuts every daemon down once it finished its job
+func (e *daemonExecutor) Close() error {
+	for i := 0; i < cap(e.slots); i++ {
+		if p := <-e.slots; p != nil {
+			p.stop()
+		}
+	}
+	return nil
+}
+
+// acquire takes a daemon that loaded its models, starting one if its slot is empty or
+// its daemon died. The daemon must be given back with release.
+func (e *daemonExecutor) acquire(ctx context.Context) (*daemonProcess, error) {
+	var p *daemonProcess
+	select {
+	case p = <-e.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if p == nil || p.hasExited() {
+		if p != nil {
+			e.logger.Warn("worker daemon exited, restarting it", zap.Error(p.exitErr))
+		}
+		var err error
+		if p, err = e.spawn(); err != nil {
+			e.slots <- nil
+			return nil, fmt.Errorf("failed to start worker daemon: %w", err)
+		}
+	}
+
+	select {
+	case <-p.ready:
+		return p, nil
+	case <-p.exited:
+		e.slots <- p
+		if startErr := p.startError(); startErr != nil {
+			return nil, fmt.Errorf("worker daemon failed to start: %w", startErr)
+		}
+		if limitErr := p.tree.limitError(); limitErr != nil {
+			return nil, fmt.Errorf("worker daemon failed to start: %w", limitErr)
+		}
+		return nil, fmt.Errorf("worker daemon failed to start: %v", p.exitErr)
+	case <-ctx.Done():
+		// Keep it loading for the next job
+		e.slots <- p
+		return nil, ctx.Err()
+	}
+}
+
+// release gives a daemon back, replacing it if it died or reached a recycling limit
+func (e *daemonExecutor) release(p *daemonProcess) {
+	var reason string
+	switch {
+	case p.hasExited():
+		e.logger.Warn("worker daemon exited, restarting it", zap.Error(p.exitErr))
+		reason = "exited"
+	case e.maxJobs > 0 && p.jobs >= e.maxJobs:
+		reason = "job limit"
+	case e.maxMemory > 0 && p.rss.Load() >= e.maxMemory:
+		reason = "memory limit"
+	default:
+		e.slots <- p
+		return
+	}
+
+	// Replace it in the background so the job returns right away
+	go func() {
+		e.logger.Info("recycling worker daemon",
+			zap.Int("pid", p.cmd.Process.Pid),
+			zap.String("reason", reason),
+			zap.Int("jobs", p.jobs),
+			zap.Int64("rss", p.rss.Load()),
+		)
+		p.stop()
+		replacement, err := e.spawn()
+		if err != nil {
+			e.logger.Error("failed to start worker daemon", zap.Error(err))
+		}
+		e.slots <- replacement
+	}()
+}
+
+// daemonProcess is one running daemon.py
+type daemonProcess struct {
+	cmd    *exec.Cmd
+	tree   *processTree
+	logger *zap.Logger
+	jobs   int // jobs sent, only used by the holder of the daemon
+
+	writeMu sync.Mutex
+	stdin   io.WriteCloser
+
+	events  chan protocol.Event // job events, closed when stdout closes
+	ready   chan struct{}       // closed once the models are loaded
+	exited  chan struct{}       // closed once the process exited
+	exitErr error               // set before exited is closed
+
+	lastPong atomic.Int64 // unix nanoseconds of the last health check answer
+	rss      atomic.Int64 // resident memory in bytes reported by the last health check
+
+	startErrMu sync.Mutex
+	startErr   *domain.WorkerError // error reported before the daemon became ready
+}
+
+// spawn starts a daemon; it loads its models in the background
+func (e *daemonExecutor) spawn() (*daemonProcess, error) {
+	daemonPyPath := filepath.Join(e.base.workerPath, "daemon.py")
+	if _, err := os.Stat(daemonPyPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("Python worker daemon not found at: %s", daemonPyPath)
+	}
+
+	cmd := exec.Command(e.base.pythonPath, daemonPyPath)
+	cmd.Dir = e.base.workerPath
+	cmd.Env = append(os.Environ(),
+		"PYTHONUNBUFFERED=1",     // Force unbuffered stdout/stderr
+		"PYTHONIOENCODING=utf-8", // Force UTF-8 for stdout/stderr pipes on Windows
+	)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	tree, err := startTree(cmd, e.base.killGrace, e.limits, e.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start worker daemon: %w", err)
+	}
+
+	p := &daemonProcess{
+		cmd:    cmd,
+		tree:   tree,
+		logger: e.logger.With(zap.Int("pid", cmd.Process.Pid)),
+		stdin:  stdin,
+		events: make(chan protocol.Event, 64),
+		ready:  make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	p.logger.Info("worker daemon started")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go p.readEvents(tree.stdout, &wg)
+	go p.readOutput(tree.stderr, &wg)
+	go func() {
+		p.exitErr = tree.wait(&wg)
+		p.logger.Info("worker daemon exited", zap.Error(p.exitErr))
+		close(p.exited)
+	}()
+	go p.checkHealth(e.healthInterval)
+
+	return p, nil
+}
+
+// readEvents dispatches the daemon's events. Job events go to the holder of the daemon,
+// which reads them until the job finished, so the daemon never blocks on them for long.
+func (p *daemonProcess) readEvents(reader io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer close(p.events)
+
+	readyOnce := sync.Once{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		event, isEvent, err := protocol.ParseLine(line)
+		if !isEvent {
+			p.logger.Info("worker stdout", zap.String("line", line))
+			continue
+		}
+		if err != nil {
+			p.logger.Warn("ignoring worker event", zap.String("line", line), zap.Error(err))
+			continue
+		}
+
+		switch {
+		case event.Type == protocol.EventReady:
+			p.lastPong.Store(time.Now().UnixNano())
+			readyOnce.Do(func() { close(p.ready) })
+			p.logger.Info("worker daemon ready")
+		case event.Type == protocol.EventPong:
+			p.lastPong.Store(time.Now().UnixNano())
+			p.rss.Store(event.RSS)
+		case event.Job != "":
+			p.events <- event
+		case event.Type == protocol.EventError:
+			p.startErrMu.Lock()
+			p.startErr = &domain.WorkerError{Code: event.Code, Message: event.Message}
+			p.startErrMu.Unlock()
+			p.logger.Error("worker daemon error", zap.String("code", event.Code), zap.String("message", event.Message))
+		case event.Type == protocol.EventWarning:
+			p.logger.Warn("worker daemon warning", zap.String("message", event.Message))
+		default:
+			p.logger.Debug("worker daemon event", zap.String("line", line))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("error reading daemon stdout", zap.Error(err))
+	}
+}
+
+// readOutput logs the daemon's human-readable output
+func (p *daemonProcess) readOutput(reader io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		p.logger.Info("worker output", zap.String("line", scanner.Text()))
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("error reading daemon stderr", zap.Error(err))
+	}
+}
+
+// checkHealth pings the daemon once it is ready and kills it when it stops answering
+func (p *daemonProcess) checkHealth(interval time.Duration) {
+	select {
+	case <-p.ready:
+	case <-p.exited:
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.exited:
+			return
+		case <-ticker.C:
+			silence := time.Since(time.Unix(0, p.lastPong.Load()))
+			if silence > missedPings*interval {
+				p.logger.Error("worker daemon stopped answering health checks, killing it",
+					zap.Duration("silence", silence),
+				)
+				p.kill()
+				return
+			}
+			if err := p.send(daemonCommand{Op: "ping"}); err != nil && !errors.Is(err, os.ErrClosed) {
+				p.logger.Warn("failed to ping worker daemon", zap.Error(err))
+			}
+		}
+	}
+}
+
+// send writes one command to the daemon
+func (p *daemonProcess) send(command daemonCommand) error {
+	line, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, err = p.stdin.Write(append(line, '\n'))
+	return err
+}
+
+// stop asks the daemon to shut down and kills it if it does not
+func (p *daemonProcess) stop() {
+	if err := p.send(daemonCommand{Op: "shutdown"}); err != nil && !errors.Is(err, os.ErrClosed) {
+		p.logger.Debug("failed to send shutdown", zap.Error(err))
+	}
+	p.writeMu.Lock()
+	p.stdin.Close()
+	p.writeMu.Unlock()
+
+	select {
+	case <-p.exited:
+	case <-time.After(daemonStopTimeout):
+		p.logger.Warn("worker daemon did not shut down, killing it")
+		p.kill()
+	}
+}
+
+// kill stops the daemon and the helpers it spawned, and waits until it exited
+func (p *daemonProcess) kill() {
+	p.tree.terminate(p.exited)
+}
+
+// hasExited reports whether the daemon process is gone
+func (p *daemonProcess) hasExited() bool {
+	select {
+	case <-p.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// startError returns the error the daemon reported before it became ready, if any
+func (p *daemonProcess) startError() *domain.WorkerError {
+	p.startErrMu.Lock()
+	defer p.startErrMu.Unlock()
+	return p.startErr
+}
@@ -8,9 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/protocol"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
 	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
@@ -20,25 +24,39 @@ import (
 type pythonExecutor struct {
 	pythonPath      string
 	workerPath      string
-	timeout         int
 	logger          *zap.Logger
 	dockerStorePath string // e.g. "/app/storage" — rewritten to localStorePath
 	localStorePath  string // e.g. "C:\Users\...\storage"
+	killGrace       time.Duration
+	limits          config.ResourceLimits
 }
 
 // NewPythonExecutor creates a new Python worker executor
 func NewPythonExecutor(cfg *config.WorkerConfig, storageCfg *config.StorageConfig, logger *zap.Logger) ports.WorkerExecutor {
+	return newPythonExecutor(cfg, storageCfg, logger)
+}
+
+func newPythonExecutor(cfg *config.WorkerConfig, storageCfg *config.StorageConfig, logger *zap.Logger) *pythonExecutor {
 	localPath, _ := filepath.Abs(storageCfg.Path)
+	if cfg.Limits != (config.ResourceLimits{}) && !limitsSupported {
+		logger.Warn("worker resource limits are only applied on Linux, ignoring them")
+	}
 	return &pythonExecutor{
 		pythonPath:      cfg.PythonPath,
 		workerPath:      cfg.WorkerPath,
-		timeout:         int(cfg.Timeout.Seconds()),
 		logger:          logger,
 		dockerStorePath: storageCfg.DockerPath,
 		localStorePath:  localPath,
+		killGrace:       cfg.KillGrace,
+		limits:          cfg.Limits,
 	}
 }
 
+// start starts a worker process in its own process group, with the configured resource limits
+func (e *pythonExecutor) start(cmd *exec.Cmd) (*processTree, error) {
+	return startTree(cmd, e.killGrace, e.limits, e.logger)
+}
+
 // rewritePath replaces the Docker container storage prefix with the local path.
 // This is needed when the API runs in Docker (storing paths like /app/storage/...)
 // but the worker runs on the host and needs the real local path.
@@ -58,63 +76,44 @@ func (e *pythonExecutor) rewritePath(path string) string {
 
 func (e *pythonExecutor) Translate(
 	ctx context.Context,
+	requestID uuid.UUID,
 	inputPath string,
+	options domain.TranslationOptions,
 	onProgress ports.ProgressCallback,
+	onPage ports.PageCallback,
 ) (*ports.TranslationOutput, error) {
-	// Rewrite Docker container path to local host path if needed
-	inputPath = e.rewritePath(inputPath)
-
-	e.logger.Info("starting translation",
-		zap.String("input_path", inputPath),
-	)
-
-	// Create job-specific temp directory
-	jobID := uuid.New().String()
-	tempDir := filepath.Join(e.localStorePath, "temp", jobID)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer func() {
-		// Cleanup temp directory
-		os.RemoveAll(tempDir)
-	}()
-
 	// Build absolute path to main.py
 	mainPyPath := filepath.Join(e.workerPath, "main.py")
 	if _, err := os.Stat(mainPyPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("Python worker not found at: %s", mainPyPath)
 	}
 
-	// inputPath is already absolute after rewritePath; ensure it's absolute
-	absInputPath, err := filepath.Abs(inputPath)
+	job, err := e.prepareJob(requestID, inputPath, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, err
 	}
+	defer job.cleanup()
+	absInputPath := job.inputPath
 
 	// Build command
-	cmd := exec.CommandContext(ctx, e.pythonPath, mainPyPath, absInputPath)
+	args := append([]string{mainPyPath}, optionArgs(options)...)
+	if job.resumeDir != "" {
+		args = append(args, "--resume-dir", job.resumeDir)
+	}
+	args = append(args, absInputPath)
+	cmd := exec.Command(e.pythonPath, args...)
 	cmd.Dir = e.workerPath // Set working directory to ai-worker
 
 	// Set environment variables with unbuffered Python output
 	cmd.Env = append(os.Environ(),
-		fmt.Sprintf("TEMP_DIR=%s", tempDir),
+		fmt.Sprintf("TEMP_DIR=%s", job.tempDir),
 		"PYTHONUNBUFFERED=1",    // Force unbuffered stdout/stderr
 		"PYTHONIOENCODING=utf-8", // Force UTF-8 for stdout/stderr pipes on Windows
 	)
 
-	// Capture stdout and stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
-
 	// Start the process
-	if err := cmd.Start(); err != nil {
+	tree, err := e.start(cmd)
+	if err != nil {
 		return nil, fmt.Errorf("failed to start worker: %w", err)
 	}
 
@@ -122,52 +121,153 @@ func (e *pythonExecutor) Translate(
 	var wg sync.WaitGroup
 	wg.Add(2)
 
-	go e.parseStdout(stdout, onProgress, &wg)
-	go e.parseStderr(stderr, &wg)
+	run := &protocol.Run{}
+	go e.parseStdout(tree.stdout, run, onProgress, onPage, &wg)
+	go e.parseStderr(tree.stderr, &wg)
 
 	// Wait for process to complete
-	done := make(chan error, 1)
+	var processErr error
+	exited := make(chan struct{})
 	go func() {
-		wg.Wait()
-		done <- cmd.Wait()
+		processErr = tree.wait(&wg)
+		close(exited)
 	}()
 
 	// Wait for completion or context cancellation
-	var processErr error
 	select {
 	case <-ctx.Done():
-		// Context cancelled, kill process
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
+		// Stop the worker along with the helpers it spawned
+		tree.terminate(exited)
 		return nil, ctx.Err()
-	case processErr = <-done:
+	case <-exited:
 		if processErr != nil {
+			if limitErr := tree.limitError(); limitErr != nil {
+				return nil, fmt.Errorf("worker process failed: %w (%v)", limitErr, processErr)
+			}
+			if run.Err != nil {
+				return nil, fmt.Errorf("worker process failed: %w (%v)", run.Err, processErr)
+			}
 			return nil, fmt.Errorf("worker process failed: %w", processErr)
 		}
 	}
 
 	// Find output files
-	output, err := e.findOutputFiles(absInputPath)
+	output, err := e.findOutputFiles(absInputPath, run.Artifact)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find output files: %w", err)
 	}
 
 	e.logger.Info("translation completed",
-		zap.String("input_path", inputPath),
+		zap.String("input_path", absInputPath),
 		zap.Int("pages", len(output.Pages)),
 	)
 
 	return output, nil
 }
 
-func (e *pythonExecutor) parseStdout(reader io.Reader, onProgress ports.ProgressCallback, wg *sync.WaitGroup) {
+// workerJob holds the files of one run of the worker
+type workerJob struct {
+	inputPath string // absolute path of the input on this host
+	tempDir   string // scratch directory of the run
+	resumeDir string // checkpoints kept across runs, empty unless the input is a ZIP
+}
+
+// cleanup removes the scratch directory; the request directory stays while another attempt uses it
+func (j *workerJob) cleanup() {
+	os.RemoveAll(j.tempDir)
+	os.Remove(filepath.Dir(j.tempDir))
+}
+
+// prepareJob resolves the input of a run and creates its directories
+func (e *pythonExecutor) prepareJob(requestID uuid.UUID, inputPath string, options domain.TranslationOptions) (*workerJob, error) {
+	// Rewrite Docker container path to local host path if needed
+	inputPath = e.rewritePath(inputPath)
+
+	e.logger.Info("starting translation",
+		zap.String("request_id", requestID.String()),
+		zap.String("input_path", inputPath),
+		zap.Any("options", options),
+	)
+
+	// inputPath is already absolute after rewritePath; ensure it's absolute
+	absInputPath, err := filepath.Abs(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	// Create job-specific temp directory, grouped by request so deleting a request can find it
+	job := &workerJob{
+		inputPath: absInputPath,
+		tempDir:   filepath.Join(e.localStorePath, "temp", requestID.String(), uuid.New().String()),
+	}
+	if err := os.MkdirAll(job.tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(absInputPath), ".zip") {
+		// Translated pages are kept across attempts so a retry resumes where this one stopped
+		job.resumeDir = filepath.Join(e.localStorePath, "checkpoints", requestID.String())
+		if err := os.MkdirAll(job.resumeDir, 0755); err != nil {
+			job.cleanup()
+			return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// optionArgs turns the options that are set into main.py flags.
+// Unset options are omitted so the worker keeps its defaults from config/settings.py.
+func optionArgs(options domain.TranslationOptions) []string {
+	var args []string
+	if options.SourceLang != "" {
+		args = append(args, "--source-lang", options.SourceLang)
+	}
+	if options.TargetLang != "" {
+		args = append(args, "--target-lang", options.TargetLang)
+	}
+	if options.Font != "" {
+		args = append(args, "--font", options.Font)
+	}
+	if options.DetectionConfidence > 0 {
+		args = append(args, "--confidence", strconv.FormatFloat(options.DetectionConfidence, 'f', -1, 64))
+	}
+	if options.OutputQuality > 0 {
+		args = append(args, "--quality", strconv.Itoa(options.OutputQuality))
+	}
+	return args
+}
+
+func (e *pythonExecutor) parseStdout(reader io.Reader, run *protocol.Run, onProgress ports.ProgressCallback, onPage ports.PageCallback, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
+
+		event, isEvent, err := protocol.ParseLine(line)
+		if isEvent {
+			if err != nil {
+				e.logger.Warn("ignoring worker event", zap.String("line", line), zap.Error(err))
+				continue
+			}
+			e.logger.Debug("worker event", zap.String("line", line))
+			run.Structured = true
+			protocol.Handle(e.logger, event, run, onProgress, onPage)
+			continue
+		}
+
 		e.logger.Info("worker stdout", zap.String("line", line))
+		if run.Structured {
+			continue
+		}
+
+		// Legacy workers only print human-readable lines
+		if update, ok := parsePageLine(line); ok {
+			if onPage != nil {
+				onPage(update)
+			}
+			continue
+		}
 
 		// Parse progress from output
 		progress, message := parseProgressLine(line)
@@ -198,7 +298,9 @@ func (e *pythonExecutor) parseStderr(reader io.Reader, wg *sync.WaitGroup) {
 	}
 }
 
-func (e *pythonExecutor) findOutputFiles(inputPath string) (*ports.TranslationOutput, error) {
+// findOutputFiles locates what the worker produced for inputPath. artifact is the output
+// file the worker reported, if any; otherwise it is looked up where the worker writes it.
+func (e *pythonExecutor) findOutputFiles(inputPath, artifact string) (*ports.TranslationOutput, error) {
 	output := &ports.TranslationOutput{
 		Pages: []ports.PageOutput{},
 	}
@@ -214,6 +316,9 @@ func (e *pythonExecutor) findOutputFiles(inputPath string) (*ports.TranslationOu
 		// The Python worker creates the translated zip in the same directory as the input
 		inputDir := filepath.Dir(inputPath)
 		translatedZip := filepath.Join(inputDir, inputName+"_translated.zip")
+		if artifact != "" {
+			translatedZip = artifact
+		}
 
 		if _, err := os.Stat(translatedZip); os.IsNotExist(err) {
 			return nil, fmt.Errorf("translated zip not found: %s", translatedZip)
@@ -230,6 +335,9 @@ func (e *pythonExecutor) findOutputFiles(inputPath string) (*ports.TranslationOu
 
 	// Single image file - Python worker creates translated_{name}.jpg in worker directory
 	translatedPath := filepath.Join(e.workerPath, "translated_"+inputName+".jpg")
+	if artifact != "" {
+		translatedPath = artifact
+	}
 
 	if _, err := os.Stat(translatedPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("translated image not found: %s", translatedPath)
@@ -0,0 +1,76 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"golang.org/x/sys/unix"
+)
+
+// limitsSupported reports whether setLimits applies resource limits on this platform
+const limitsSupported = true
+
+// setLimits lowers the rlimits of process pid; children it spawns afterwards inherit them
+func setLimits(pid int, limits config.ResourceLimits) error {
+	if limits.MemoryMB > 0 {
+		bytes := uint64(limits.MemoryMB) << 20
+		if err := unix.Prlimit(pid, unix.RLIMIT_DATA, &unix.Rlimit{Cur: bytes, Max: bytes}, nil); err != nil {
+			return err
+		}
+	}
+	if limits.CPUTime > 0 {
+		// SIGXCPU at the soft limit ends the worker; SIGKILL at the hard limit ends it if it
+		// ignores that signal
+		seconds := uint64(limits.CPUTime.Seconds())
+		if err := unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: seconds, Max: seconds + 1}, nil); err != nil {
+			return err
+		}
+	}
+	if limits.OpenFiles > 0 {
+		files := uint64(limits.OpenFiles)
+		if err := unix.Prlimit(pid, unix.RLIMIT_NOFILE, &unix.Rlimit{Cur: files, Max: files}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oomKills returns how many processes the OOM killer ended, in the cgroup of the backend,
+// which its workers share, plus system-wide. It is -1 if the kernel reports neither.
+func oomKills() int64 {
+	total := int64(-1)
+	add := func(path, key string) {
+		if n, ok := readCounter(path, key); ok {
+			total = max(total, 0) + n
+		}
+	}
+
+	// cgroup v2 lists a single "0::<path>" line
+	if cgroups, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		for _, line := range strings.Split(string(cgroups), "\n") {
+			if path, ok := strings.CutPrefix(line, "0::"); ok {
+				add(filepath.Join("/sys/fs/cgroup", path, "memory.events"), "oom_kill")
+			}
+		}
+	}
+	add("/proc/vmstat", "oom_kill")
+	return total
+}
+
+// readCounter reads the "key value" line of key in a kernel statistics file
+func readCounter(path, key string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, key+" "); ok {
+			n, err := strconv.ParseInt(value, 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
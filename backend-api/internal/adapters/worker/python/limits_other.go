@@ -0,0 +1,18 @@
+//go:build !linux
+
+package python
+
+import "github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+
+// limitsSupported reports whether setLimits applies resource limits on this platform
+const limitsSupported = false
+
+// setLimits does nothing: another process's rlimits can only be set on Linux
+func setLimits(pid int, limits config.ResourceLimits) error {
+	return nil
+}
+
+// oomKills returns -1, the kernel does not count OOM kills on this platform
+func oomKills() int64 {
+	return -1
+}
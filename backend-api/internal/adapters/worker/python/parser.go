@@ -4,6 +4,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 )
 
 var (
@@ -13,8 +16,22 @@ var (
 	progressPattern   = regexp.MustCompile(`PROGRESS:\s+(\d+)%\s+-\s+(.+)`)
 	completedPattern  = regexp.MustCompile(`Created:\s+(.+)`)
 	errorPattern      = regexp.MustCompile(`❌\s+(.+)`)
+	pagePattern       = regexp.MustCompile(`^PAGE:\s+(processing|done|skipped|failed)\s+(.+?)(?:\s+\|\s+(.*))?$`)
 )
 
+// parsePageLine parses a page report: "PAGE: skipped chapter/003.png | cannot identify image file"
+func parsePageLine(line string) (ports.PageUpdate, bool) {
+	matches := pagePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return ports.PageUpdate{}, false
+	}
+	return ports.PageUpdate{
+		Name:   matches[2],
+		Status: domain.PageStatus(matches[1]),
+		Error:  matches[3],
+	}, true
+}
+
 // parseProgressLine parses a line from the worker's stdout and extracts progress information
 // Returns (progress percentage, message)
 func parseProgressLine(line string) (int, string) {
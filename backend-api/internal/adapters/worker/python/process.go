@@ -0,0 +1,130 @@
+package python
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+// processTree is a worker process started in its own process group, so stopping it also
+// stops the helper processes it spawned, like those of torch or llama.cpp, which would
+// otherwise keep holding VRAM and RAM
+type processTree struct {
+	cmd      *exec.Cmd
+	grace    time.Duration
+	limits   config.ResourceLimits
+	logger   *zap.Logger
+	stopped  atomic.Bool // set once the executor started stopping the process
+	oomKills int64       // OOM kills counted before the worker started, -1 if unknown
+
+	// stdout and stderr are the read ends of the worker's output. Unlike those of
+	// cmd.StdoutPipe, cmd.Wait leaves them open, so they can be drained after it.
+	stdout, stderr *os.File
+}
+
+// startTree starts cmd in a new process group with its output connected to the pipes of
+// the tree, and applies limits to it right away, before the interpreter gets to load anything
+func startTree(cmd *exec.Cmd, grace time.Duration, limits config.ResourceLimits, logger *zap.Logger) (*processTree, error) {
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdout.Close()
+		stdoutWriter.Close()
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	cmd.Stdout, cmd.Stderr = stdoutWriter, stderrWriter
+
+	setProcessGroup(cmd)
+	kills := oomKills()
+	err = cmd.Start()
+	// The worker holds its own copies of the write ends
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return nil, err
+	}
+
+	if err := setLimits(cmd.Process.Pid, limits); err != nil {
+		signalTree(cmd.Process, true)
+		cmd.Wait()
+		stdout.Close()
+		stderr.Close()
+		return nil, fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+	return &processTree{
+		cmd:      cmd,
+		grace:    grace,
+		limits:   limits,
+		logger:   logger.With(zap.Int("pid", cmd.Process.Pid)),
+		oomKills: kills,
+		stdout:   stdout,
+		stderr:   stderr,
+	}, nil
+}
+
+// wait waits for the worker to exit and kills the helpers it left in its process group,
+// then waits for drained, the readers of its output, and closes that output. Draining it
+// first would block for as long as a helper keeps it open.
+func (t *processTree) wait(drained *sync.WaitGroup) error {
+	err := t.cmd.Wait()
+	if killErr := signalTree(t.cmd.Process, true); killErr != nil {
+		t.logger.Warn("failed to kill worker helpers", zap.Error(killErr))
+	}
+	drained.Wait()
+	t.stdout.Close()
+	t.stderr.Close()
+	return err
+}
+
+// terminate sends SIGTERM to the process group and SIGKILL once the grace period passed
+// without exited being closed. Helpers still alive after the worker exited are killed too.
+// It returns once exited is closed, or after a second grace period if it is not.
+func (t *processTree) terminate(exited <-chan struct{}) {
+	t.stopped.Store(true)
+	if err := signalTree(t.cmd.Process, false); err != nil {
+		t.logger.Warn("failed to terminate worker", zap.Error(err))
+	}
+
+	timer := time.NewTimer(t.grace)
+	defer timer.Stop()
+	select {
+	case <-exited:
+		signalTree(t.cmd.Process, true)
+		return
+	case <-timer.C:
+	}
+
+	t.logger.Warn("worker did not exit after SIGTERM, killing it", zap.Duration("grace", t.grace))
+	if err := signalTree(t.cmd.Process, true); err != nil {
+		t.logger.Error("failed to kill worker", zap.Error(err))
+	}
+	timer.Reset(t.grace)
+	select {
+	case <-exited:
+	case <-timer.C:
+		t.logger.Error("worker output still open after SIGKILL, a helper may have left its process group")
+	}
+}
+
+// limitError explains the exit of a worker the executor did not stop, when a resource
+// limit, the OOM killer or another signal ended it. It is nil otherwise, and until the
+// process was waited for.
+func (t *processTree) limitError() *domain.WorkerError {
+	if t.stopped.Load() || t.cmd.ProcessState == nil {
+		return nil
+	}
+	oomKilled := t.oomKills >= 0 && oomKills() > t.oomKills
+	return exitLimitError(t.cmd.ProcessState, t.limits, oomKilled)
+}
@@ -0,0 +1,28 @@
+//go:build !unix
+
+package python
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+)
+
+// setProcessGroup does nothing: without process groups only the worker itself is stopped
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalTree kills the worker; there is no SIGTERM to send first
+func signalTree(p *os.Process, force bool) error {
+	if err := p.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// exitLimitError reports nothing, the exit status does not tell why the worker died
+func exitLimitError(state *os.ProcessState, limits config.ResourceLimits, oomKilled bool) *domain.WorkerError {
+	return nil
+}
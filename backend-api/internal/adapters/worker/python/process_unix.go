@@ -0,0 +1,62 @@
+//go:build unix
+
+package python
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+)
+
+// setProcessGroup makes cmd the leader of a new process group, which its children join
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalTree sends SIGTERM, or SIGKILL when force is set, to the process group led by p
+func signalTree(p *os.Process, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	if err := syscall.Kill(-p.Pid, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+// exitLimitError reports a worker killed by a signal the backend did not send. SIGXCPU, or
+// SIGKILL once the CPU time is used up, is the CPU time limit; a SIGKILL is only blamed on
+// the OOM killer when oomKilled confirms it, as an operator or supervisor may have sent it.
+func exitLimitError(state *os.ProcessState, limits config.ResourceLimits, oomKilled bool) *domain.WorkerError {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+
+	cpuLimited := limits.CPUTime > 0 && state.UserTime()+state.SystemTime() >= limits.CPUTime
+	switch {
+	case status.Signal() == syscall.SIGXCPU, status.Signal() == syscall.SIGKILL && cpuLimited:
+		return &domain.WorkerError{
+			Code:    domain.WorkerCodeCPULimit,
+			Message: fmt.Sprintf("worker used up its CPU time limit of %s", limits.CPUTime),
+		}
+	case status.Signal() == syscall.SIGKILL && oomKilled:
+		return &domain.WorkerError{
+			Code:    domain.WorkerCodeOutOfMemory,
+			Message: "worker was killed by the OOM killer",
+		}
+	}
+	return &domain.WorkerError{
+		Code:    domain.WorkerCodeKilled,
+		Message: fmt.Sprintf("worker was killed by signal %s", status.Signal()),
+	}
+}
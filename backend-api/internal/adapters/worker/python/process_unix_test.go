@@ -0,0 +1,101 @@
+//go:build unix
+
+package python
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+// startShell runs script in a process tree; exited is closed once the tree was waited for
+// and its output drained
+func startShell(t *testing.T, script string, limits config.ResourceLimits) (tree *processTree, exited chan struct{}) {
+	tree, err := startTree(exec.Command("sh", "-c", script), 5*time.Second, limits, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var drained sync.WaitGroup
+	drained.Add(2)
+	for _, output := range []io.Reader{tree.stdout, tree.stderr} {
+		go func() {
+			io.Copy(io.Discard, output)
+			drained.Done()
+		}()
+	}
+	exited = make(chan struct{})
+	go func() {
+		tree.wait(&drained)
+		close(exited)
+	}()
+	return tree, exited
+}
+
+func TestTerminateStopsHelpers(t *testing.T) {
+	// The helper keeps the output open, so exited is only closed once it is gone too
+	tree, exited := startShell(t, "sleep 60 & wait", config.ResourceLimits{})
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	tree.terminate(exited)
+	select {
+	case <-exited:
+	default:
+		t.Fatal("worker output still open after terminate")
+	}
+	if elapsed := time.Since(start); elapsed >= tree.grace {
+		t.Errorf("terminate took %s, want less than the %s grace period", elapsed, tree.grace)
+	}
+	if err := tree.limitError(); err != nil {
+		t.Errorf("limit error of a stopped worker: got %v, want nil", err)
+	}
+}
+
+func TestWaitStopsHelpersOfExitedWorker(t *testing.T) {
+	// The worker exits right away, the helper it leaves behind keeps the output open
+	tree, exited := startShell(t, "sleep 60 & exit 0", config.ResourceLimits{})
+
+	select {
+	case <-exited:
+	case <-time.After(tree.grace):
+		t.Fatal("worker output still open after the worker exited")
+	}
+	if !tree.cmd.ProcessState.Success() {
+		t.Errorf("exit of the worker: got %s, want success", tree.cmd.ProcessState)
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		limits    config.ResourceLimits
+		oomKilled bool // the kernel counted an OOM kill meanwhile
+		wantCode  string
+	}{
+		{name: "failure", script: "exit 1"},
+		{name: "oom kill", script: "kill -KILL $$", oomKilled: true, wantCode: domain.WorkerCodeOutOfMemory},
+		{name: "kill", script: "kill -KILL $$", wantCode: domain.WorkerCodeKilled},
+		{name: "other signal", script: "kill -SEGV $$", wantCode: domain.WorkerCodeKilled},
+		{name: "cpu limit", script: "kill -XCPU $$", limits: config.ResourceLimits{CPUTime: time.Minute}, wantCode: domain.WorkerCodeCPULimit},
+	}
+
+	for _, tt := range tests {
+		tree, exited := startShell(t, tt.script, tt.limits)
+		<-exited
+
+		err := exitLimitError(tree.cmd.ProcessState, tt.limits, tt.oomKilled)
+		switch {
+		case tt.wantCode == "" && err != nil:
+			t.Errorf("%s: got %v, want nil", tt.name, err)
+		case tt.wantCode != "" && (err == nil || err.Code != tt.wantCode):
+			t.Errorf("%s: got %v, want code %q", tt.name, err, tt.wantCode)
+		}
+	}
+}
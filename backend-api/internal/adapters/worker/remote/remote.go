@@ -0,0 +1,335 @@
+// Package remote implements a worker executor that runs jobs on the worker service of
+// another machine over HTTP.
+package remote
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/protocol"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// JobsPath is the endpoint of the worker service that runs a job.
+// The input is the request body, the job ID, file name and options are query parameters,
+// and the response streams one JSON event per line until a job_finished event.
+const JobsPath = "/v1/jobs"
+
+// maxEventSize bounds one line of the remote event stream; file events carry a whole page
+const maxEventSize = 64 << 20
+
+// The worker service runs one job at a time and answers 503 to the others, which wait
+// busyBackoff before sending the job again, doubled on every busy answer up to maxBusyBackoff
+const (
+	busyBackoff    = 500 * time.Millisecond
+	maxBusyBackoff = 30 * time.Second
+)
+
+// executor sends jobs to a worker service over HTTP, so the worker needs no access to
+// the storage. The translated pages come back in the event stream and are written locally.
+type executor struct {
+	url    string
+	token  string
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewExecutor creates a worker executor that runs jobs on the worker service at cfg.Remote.URL
+func NewExecutor(cfg *config.WorkerConfig, logger *zap.Logger) ports.WorkerExecutor {
+	return &executor{
+		url:    strings.TrimSuffix(cfg.Remote.URL, "/"),
+		token:  cfg.Remote.Token,
+		client: &http.Client{}, // jobs are bounded by their context, not by a client timeout
+		logger: logger,
+	}
+}
+
+func (e *executor) Translate(
+	ctx context.Context,
+	requestID uuid.UUID,
+	inputPath string,
+	options domain.TranslationOptions,
+	onProgress ports.ProgressCallback,
+	onPage ports.PageCallback,
+) (*ports.TranslationOutput, error) {
+	e.logger.Info("starting remote translation",
+		zap.String("request_id", requestID.String()),
+		zap.String("input_path", inputPath),
+		zap.Any("options", options),
+	)
+
+	jobID := uuid.New().String()
+	query := jobQuery(jobID, filepath.Base(inputPath), options)
+	resp, err := e.send(ctx, inputPath, query, onProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("worker service answered %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	out, err := newJobOutput(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &protocol.Run{Structured: true}
+	status, err := e.readEvents(resp.Body, jobID, out, run, onProgress, onPage)
+	if ctx.Err() != nil {
+		out.discard()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		out.discard()
+		return nil, err
+	}
+	if status != "done" {
+		out.discard()
+		if run.Err != nil {
+			return nil, fmt.Errorf("worker job failed: %w", run.Err)
+		}
+		return nil, fmt.Errorf("worker job %s", status)
+	}
+
+	output, err := out.close(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	e.logger.Info("remote translation completed",
+		zap.String("input_path", inputPath),
+		zap.Int("files", out.files),
+	)
+	return output, nil
+}
+
+// send posts a job, sending it again with backoff while the worker service is busy
+func (e *executor) send(ctx context.Context, inputPath string, query url.Values, onProgress ports.ProgressCallback) (*http.Response, error) {
+	delay := busyBackoff
+	for {
+		resp, err := e.post(ctx, inputPath, query)
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, err
+		}
+		resp.Body.Close()
+
+		e.logger.Debug("worker service busy, waiting", zap.Duration("retry_in", delay))
+		if onProgress != nil {
+			// Also keeps the job's stall watchdog from firing while it waits
+			onProgress(0, "Waiting for the worker service")
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxBusyBackoff)
+	}
+}
+
+// post sends the input of a job to the worker service once
+func (e *executor) post(ctx context.Context, inputPath string, query url.Values) (*http.Response, error) {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	info, err := input.Stat()
+	if err != nil {
+		input.Close()
+		return nil, fmt.Errorf("failed to stat input: %w", err)
+	}
+
+	// The client closes input once it is sent
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+JobsPath+"?"+query.Encode(), input)
+	if err != nil {
+		input.Close()
+		return nil, fmt.Errorf("failed to build worker request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to reach worker service: %w", err)
+	}
+	return resp, nil
+}
+
+// readEvents applies the event stream of a job and returns the status it finished with
+func (e *executor) readEvents(
+	body io.Reader,
+	jobID string,
+	out *jobOutput,
+	run *protocol.Run,
+	onProgress ports.ProgressCallback,
+	onPage ports.PageCallback,
+) (string, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		event, err := protocol.Parse(scanner.Bytes())
+		if err != nil {
+			e.logger.Warn("ignoring worker event", zap.Error(err))
+			continue
+		}
+		if event.Job != "" && event.Job != jobID {
+			e.logger.Debug("ignoring event of another job", zap.String("job", event.Job), zap.String("type", event.Type))
+			continue
+		}
+
+		switch event.Type {
+		case protocol.EventFile:
+			if err := out.add(event.Name, event.Data); err != nil {
+				return "", err
+			}
+		case protocol.EventJobFinished:
+			return event.Status, nil
+		default:
+			protocol.Handle(e.logger, event, run, onProgress, onPage)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("lost connection to worker service: %w", err)
+	}
+	if run.Err != nil {
+		return "", fmt.Errorf("worker service stopped: %w", run.Err)
+	}
+	return "", errors.New("worker service closed the stream before the job finished")
+}
+
+// jobQuery encodes a job's parameters; unset options are omitted so the worker keeps its defaults
+func jobQuery(jobID, filename string, options domain.TranslationOptions) url.Values {
+	query := url.Values{}
+	query.Set("job", jobID)
+	query.Set("filename", filename)
+	if options.SourceLang != "" {
+		query.Set("source_lang", options.SourceLang)
+	}
+	if options.TargetLang != "" {
+		query.Set("target_lang", options.TargetLang)
+	}
+	if options.Font != "" {
+		query.Set("font", options.Font)
+	}
+	if options.DetectionConfidence > 0 {
+		query.Set("confidence", strconv.FormatFloat(options.DetectionConfidence, 'f', -1, 64))
+	}
+	if options.OutputQuality > 0 {
+		query.Set("quality", strconv.Itoa(options.OutputQuality))
+	}
+	return query
+}
+
+// jobOutput writes the files received from the worker service where the local worker
+// would have written them: a ZIP next to a ZIP input, an image next to an image input.
+type jobOutput struct {
+	path  string
+	file  *os.File
+	zip   *zip.Writer // nil for an image input
+	files int
+}
+
+func newJobOutput(inputPath string) (*jobOutput, error) {
+	dir := filepath.Dir(inputPath)
+	base := filepath.Base(inputPath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+
+	if !strings.EqualFold(filepath.Ext(inputPath), ".zip") {
+		return &jobOutput{path: filepath.Join(dir, "translated_"+name+".jpg")}, nil
+	}
+
+	out := &jobOutput{path: filepath.Join(dir, name+"_translated.zip")}
+	file, err := os.Create(out.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translated zip: %w", err)
+	}
+	out.file = file
+	out.zip = zip.NewWriter(file)
+	return out, nil
+}
+
+// add stores one translated file; name is its slash-separated path inside the input
+func (o *jobOutput) add(name string, data []byte) error {
+	if o.zip == nil {
+		if o.files > 0 {
+			return errors.New("worker service sent more than one page for an image")
+		}
+		o.files++
+		if err := os.WriteFile(o.path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write translated page: %w", err)
+		}
+		return nil
+	}
+
+	clean := path.Clean(name)
+	if name == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("worker service sent an invalid page name: %q", name)
+	}
+	entry, err := o.zip.Create(clean)
+	if err != nil {
+		return fmt.Errorf("failed to add translated page: %w", err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("failed to add translated page: %w", err)
+	}
+	o.files++
+	return nil
+}
+
+// close finishes the output and describes it like the local executors do
+func (o *jobOutput) close(inputPath string) (*ports.TranslationOutput, error) {
+	if o.zip == nil {
+		if o.files == 0 {
+			return nil, errors.New("worker service sent no translated page")
+		}
+		return &ports.TranslationOutput{
+			OutputPath: o.path,
+			Pages: []ports.PageOutput{
+				{PageNumber: 1, OriginalPath: inputPath, TranslatedPath: o.path},
+			},
+		}, nil
+	}
+
+	if err := o.zip.Close(); err != nil {
+		o.file.Close()
+		return nil, fmt.Errorf("failed to write translated zip: %w", err)
+	}
+	if err := o.file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write translated zip: %w", err)
+	}
+	return &ports.TranslationOutput{OutputPath: o.path, Pages: []ports.PageOutput{}}, nil
+}
+
+// discard removes whatever was written for a job that did not finish
+func (o *jobOutput) discard() {
+	if o.file != nil {
+		o.file.Close()
+	}
+	os.Remove(o.path)
+}
@@ -0,0 +1,206 @@
+package remote_test
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/remote"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/stub"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/testutil"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newRemoteExecutor(t *testing.T, opts stub.Options, token string) ports.WorkerExecutor {
+	server := httptest.NewServer(stub.NewHandler(opts))
+	t.Cleanup(server.Close)
+	cfg := &config.WorkerConfig{Remote: config.RemoteWorkerConfig{URL: server.URL, Token: token}}
+	return remote.NewExecutor(cfg, zap.NewNop())
+}
+
+func TestRemoteExecutorZip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "chapter.zip")
+	testutil.WriteZip(t, input, map[string][]byte{
+		"ch1/001.jpg": []byte("page one"),
+		"ch1/002.png": []byte("page two"),
+		"notes.txt":   []byte("ignored"),
+	})
+
+	var updates []ports.PageUpdate
+	executor := newRemoteExecutor(t, stub.Options{Token: "secret"}, "secret")
+	output, err := executor.Translate(context.Background(), uuid.New(), input, domain.TranslationOptions{}, nil,
+		func(update ports.PageUpdate) { updates = append(updates, update) })
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	wantPath := filepath.Join(dir, "chapter_translated.zip")
+	if output.OutputPath != wantPath {
+		t.Errorf("output path: got %s, want %s", output.OutputPath, wantPath)
+	}
+	wantUpdates := []ports.PageUpdate{
+		{Name: "ch1/001.jpg", Status: domain.PageStatusProcessing},
+		{Name: "ch1/001.jpg", Status: domain.PageStatusDone},
+		{Name: "ch1/002.png", Status: domain.PageStatusProcessing},
+		{Name: "ch1/002.png", Status: domain.PageStatusDone},
+	}
+	if !reflect.DeepEqual(updates, wantUpdates) {
+		t.Errorf("page updates: got %+v, want %+v", updates, wantUpdates)
+	}
+
+	archive, err := zip.OpenReader(wantPath)
+	if err != nil {
+		t.Fatalf("open translated zip: %v", err)
+	}
+	defer archive.Close()
+	got := make(map[string]string)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name] = string(data)
+	}
+	want := map[string]string{"ch1/001.jpg": "page one", "ch1/002.png": "page two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("translated pages: got %v, want %v", got, want)
+	}
+}
+
+func TestRemoteExecutorImage(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "cover.png")
+	if err := os.WriteFile(input, []byte("cover"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := newRemoteExecutor(t, stub.Options{}, "")
+	output, err := executor.Translate(context.Background(), uuid.New(), input, domain.TranslationOptions{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	wantPath := filepath.Join(dir, "translated_cover.jpg")
+	if output.OutputPath != wantPath || len(output.Pages) != 1 || output.Pages[0].TranslatedPath != wantPath {
+		t.Errorf("output: got %+v, want one page at %s", output, wantPath)
+	}
+	if data, err := os.ReadFile(wantPath); err != nil || string(data) != "cover" {
+		t.Errorf("translated page: got %q (%v), want %q", data, err, "cover")
+	}
+}
+
+func TestRemoteExecutorErrors(t *testing.T) {
+	dir := t.TempDir()
+	notZip := filepath.Join(dir, "broken.zip")
+	if err := os.WriteFile(notZip, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{name: "invalid token", token: "wrong"},
+		{name: "invalid input", token: "secret", wantCode: domain.WorkerCodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		executor := newRemoteExecutor(t, stub.Options{Token: "secret"}, tt.token)
+		_, err := executor.Translate(context.Background(), uuid.New(), notZip, domain.TranslationOptions{}, nil, nil)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		var workerErr *domain.WorkerError
+		if errors.As(err, &workerErr) != (tt.wantCode != "") || (workerErr != nil && workerErr.Code != tt.wantCode) {
+			t.Errorf("%s: got %v, want worker error code %q", tt.name, err, tt.wantCode)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "broken_translated.zip")); !os.IsNotExist(err) {
+			t.Errorf("%s: partial output was not removed", tt.name)
+		}
+	}
+}
+
+func TestRemoteExecutorCancel(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "chapter.zip")
+	testutil.WriteZip(t, input, map[string][]byte{"001.jpg": []byte("a"), "002.jpg": []byte("b"), "003.jpg": []byte("c")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	executor := newRemoteExecutor(t, stub.Options{PageDelay: time.Hour}, "")
+	_, err := executor.Translate(ctx, uuid.New(), input, domain.TranslationOptions{}, nil,
+		func(update ports.PageUpdate) {
+			if update.Status == domain.PageStatusProcessing {
+				cancel()
+			}
+		})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chapter_translated.zip")); !os.IsNotExist(err) {
+		t.Errorf("partial output was not removed")
+	}
+}
+
+func TestRemoteExecutorWaitsWhileBusy(t *testing.T) {
+	server := httptest.NewServer(stub.NewHandler(stub.Options{PageDelay: 200 * time.Millisecond}))
+	t.Cleanup(server.Close)
+	cfg := &config.WorkerConfig{Remote: config.RemoteWorkerConfig{URL: server.URL}}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.zip")
+	second := filepath.Join(dir, "second.zip")
+	testutil.WriteZip(t, first, map[string][]byte{"001.jpg": []byte("a"), "002.jpg": []byte("b")})
+	testutil.WriteZip(t, second, map[string][]byte{"001.jpg": []byte("c")})
+
+	// The second job is sent while the service still runs the first
+	started := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		var once bool
+		_, err := remote.NewExecutor(cfg, zap.NewNop()).Translate(context.Background(), uuid.New(), first,
+			domain.TranslationOptions{}, nil, func(ports.PageUpdate) {
+				if !once {
+					once = true
+					close(started)
+				}
+			})
+		firstDone <- err
+	}()
+	<-started
+
+	var waited bool
+	_, err := remote.NewExecutor(cfg, zap.NewNop()).Translate(context.Background(), uuid.New(), second,
+		domain.TranslationOptions{}, func(_ int, message string) {
+			if message == "Waiting for the worker service" {
+				waited = true
+			}
+		}, nil)
+	if err != nil {
+		t.Errorf("second job: %v", err)
+	}
+	if !waited {
+		t.Errorf("second job did not wait for the busy service")
+	}
+	if err := <-firstDone; err != nil {
+		t.Errorf("first job: %v", err)
+	}
+}
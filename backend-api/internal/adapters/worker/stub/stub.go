@@ -0,0 +1,170 @@
+// Package stub implements a fake remote worker service for tests and local development.
+// It speaks the protocol of the remote executor but skips the model: every page comes
+// back untouched.
+package stub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/adapters/worker/remote"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/pkg/natsort"
+)
+
+// Options configures the stub worker service
+type Options struct {
+	Token     string        // bearer token the requests must carry, none if empty
+	PageDelay time.Duration // time spent "translating" each page
+}
+
+// Handler serves the jobs endpoint of the worker service, one job at a time like the real one
+type Handler struct {
+	opts Options
+	busy sync.Mutex
+}
+
+// NewHandler creates a stub worker service
+func NewHandler(opts Options) *Handler {
+	return &Handler{opts: opts}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != remote.JobsPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.opts.Token != "" && r.Header.Get("Authorization") != "Bearer "+h.opts.Token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	if !h.busy.TryLock() {
+		http.Error(w, "worker busy", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.busy.Unlock()
+
+	input, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read input", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	job := &job{
+		id:      query.Get("job"),
+		w:       w,
+		flusher: w.(http.Flusher),
+		delay:   h.opts.PageDelay,
+		done:    r.Context().Done(),
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	job.run(query.Get("filename"), input)
+}
+
+// job streams the events of one job
+type job struct {
+	id      string
+	w       io.Writer
+	flusher http.Flusher
+	delay   time.Duration
+	done    <-chan struct{}
+}
+
+func (j *job) emit(event map[string]any) {
+	event["v"] = 1
+	event["job"] = j.id
+	line, _ := json.Marshal(event)
+	j.w.Write(append(line, '\n'))
+	j.flusher.Flush()
+}
+
+func (j *job) fail(code, message string) {
+	j.emit(map[string]any{"type": "error", "code": code, "message": message})
+	j.emit(map[string]any{"type": "job_finished", "status": "failed"})
+}
+
+func (j *job) run(filename string, input []byte) {
+	j.emit(map[string]any{"type": "stage_started", "stage": "init"})
+	j.emit(map[string]any{"type": "stage_finished", "stage": "init"})
+
+	if !strings.EqualFold(path.Ext(filename), ".zip") {
+		if !j.page(1, 1, filename, "translated_"+strings.TrimSuffix(filename, path.Ext(filename))+".jpg", input) {
+			return
+		}
+		j.emit(map[string]any{"type": "job_finished", "status": "done"})
+		return
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(input), int64(len(input)))
+	if err != nil {
+		j.fail("invalid_input", "Invalid ZIP: "+err.Error())
+		return
+	}
+	files := make(map[string]*zip.File)
+	var names []string
+	for _, f := range archive.File {
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".jpg", ".jpeg", ".png", ".webp", ".bmp":
+			files[f.Name] = f
+			names = append(names, f.Name)
+		}
+	}
+	if len(names) == 0 {
+		j.fail("invalid_input", "No images found in ZIP")
+		return
+	}
+	natsort.Sort(names)
+
+	j.emit(map[string]any{"type": "stage_started", "stage": "translate", "total": len(names)})
+	for i, name := range names {
+		data, err := readFile(files[name])
+		if err != nil {
+			j.emit(map[string]any{"type": "page", "status": "failed", "name": name, "index": i + 1, "total": len(names), "error": err.Error()})
+			continue
+		}
+		if !j.page(i+1, len(names), name, name, data) {
+			return
+		}
+		j.emit(map[string]any{
+			"type":    "progress",
+			"percent": 5 + 90*(i+1)/len(names),
+			"message": "Translated " + name,
+		})
+	}
+	j.emit(map[string]any{"type": "stage_finished", "stage": "translate"})
+	j.emit(map[string]any{"type": "job_finished", "status": "done"})
+}
+
+// page "translates" one page and sends it back; false if the client went away
+func (j *job) page(index, total int, name, output string, data []byte) bool {
+	j.emit(map[string]any{"type": "page", "status": "processing", "name": name, "index": index, "total": total})
+	select {
+	case <-j.done:
+		return false
+	case <-time.After(j.delay):
+	}
+	j.emit(map[string]any{"type": "file", "name": output, "data": data})
+	j.emit(map[string]any{"type": "page", "status": "done", "name": name, "index": index, "total": total})
+	return true
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,255 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CleanupService applies the storage retention policies
+type CleanupService struct {
+	requestRepo    ports.RequestRepository
+	requestService *RequestService
+	storagePath    string
+	policy         config.RetentionConfig
+	logger         *zap.Logger
+}
+
+// NewCleanupService creates a new cleanup service
+func NewCleanupService(
+	requestRepo ports.RequestRepository,
+	requestService *RequestService,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *CleanupService {
+	return &CleanupService{
+		requestRepo:    requestRepo,
+		requestService: requestService,
+		storagePath:    cfg.Storage.Path,
+		policy:         cfg.Retention,
+		logger:         logger,
+	}
+}
+
+// Cleanup runs every enabled retention policy once.
+// In dry-run mode nothing is deleted and the report tells what would have been reclaimed.
+func (s *CleanupService) Cleanup(ctx context.Context) (*domain.CleanupReport, error) {
+	report := &domain.CleanupReport{DryRun: s.policy.DryRun}
+	now := time.Now()
+
+	if s.policy.UploadAge > 0 {
+		if err := s.removeUploads(ctx, now.Add(-s.policy.UploadAge), report); err != nil {
+			return report, err
+		}
+	}
+
+	if s.policy.FailedAge > 0 {
+		if err := s.purgeFailed(ctx, now.Add(-s.policy.FailedAge), report); err != nil {
+			return report, err
+		}
+	}
+
+	if s.policy.OrphanAge > 0 {
+		if err := s.sweepOrphans(ctx, now.Add(-s.policy.OrphanAge), report); err != nil {
+			return report, err
+		}
+	}
+
+	s.logger.Info("storage cleanup finished",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("uploads_removed", report.UploadsRemoved),
+		zap.Int("requests_purged", report.RequestsPurged),
+		zap.Int("orphans_removed", report.OrphansRemoved),
+		zap.Int64("bytes_reclaimed", report.BytesReclaimed),
+	)
+
+	return report, nil
+}
+
+// removeUploads deletes the uploaded files of requests completed (fully or partially) before
+// cutoff. Results stay available, but such requests can no longer be retried.
+func (s *CleanupService) removeUploads(ctx context.Context, cutoff time.Time, report *domain.CleanupReport) error {
+	var requests []*domain.Request
+	for _, status := range []domain.RequestStatus{domain.StatusCompleted, domain.StatusPartial} {
+		finished, err := s.finishedBefore(ctx, status, cutoff)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, finished...)
+	}
+
+	for _, request := range requests {
+		path := filepath.Join(s.storagePath, "uploads", request.ID.String())
+		size, _, err := dirUsage(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("failed to measure upload", zap.String("path", path), zap.Error(err))
+			continue
+		}
+
+		if s.remove(path) {
+			report.UploadsRemoved++
+			report.BytesReclaimed += size
+		}
+	}
+
+	return nil
+}
+
+// purgeFailed deletes failed and cancelled requests that finished before cutoff, with all their files
+func (s *CleanupService) purgeFailed(ctx context.Context, cutoff time.Time, report *domain.CleanupReport) error {
+	for _, status := range []domain.RequestStatus{domain.StatusFailed, domain.StatusCancelled} {
+		requests, err := s.finishedBefore(ctx, status, cutoff)
+		if err != nil {
+			return err
+		}
+
+		for _, request := range requests {
+			var size int64
+			for _, dir := range storageDirs {
+				dirSize, _, _ := dirUsage(filepath.Join(s.storagePath, dir, request.ID.String()))
+				size += dirSize
+			}
+
+			if s.policy.DryRun {
+				s.logger.Info("would purge request",
+					zap.String("request_id", request.ID.String()),
+					zap.String("status", string(request.Status)),
+					zap.Timep("completed_at", request.CompletedAt),
+					zap.Int64("bytes", size),
+				)
+			} else {
+				if err := s.requestService.Delete(ctx, request.ID, false); err != nil {
+					s.logger.Warn("failed to purge request",
+						zap.String("request_id", request.ID.String()),
+						zap.Error(err),
+					)
+					continue
+				}
+			}
+
+			report.RequestsPurged++
+			report.BytesReclaimed += size
+		}
+	}
+
+	return nil
+}
+
+// sweepOrphans removes directories left untouched since cutoff that no request owns,
+// and temp directories of requests that are no longer processing
+func (s *CleanupService) sweepOrphans(ctx context.Context, cutoff time.Time, report *domain.CleanupReport) error {
+	for _, dir := range storageDirs {
+		entries, err := os.ReadDir(filepath.Join(s.storagePath, dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(s.storagePath, dir, entry.Name())
+			size, modified, err := dirUsage(path)
+			if err != nil || modified.After(cutoff) {
+				// Unfinished tus uploads and running jobs keep touching their directory
+				continue
+			}
+
+			orphan, err := s.isOrphan(ctx, dir, entry.Name())
+			if err != nil {
+				return err
+			}
+			if orphan && s.remove(path) {
+				report.OrphansRemoved++
+				report.BytesReclaimed += size
+			}
+		}
+	}
+
+	return nil
+}
+
+// isOrphan reports whether a storage directory belongs to no request.
+// Temp directories are also orphaned once their request stopped processing,
+// checkpoints once their request finished with results.
+func (s *CleanupService) isOrphan(ctx context.Context, dir, name string) (bool, error) {
+	id, err := uuid.Parse(name)
+	if err != nil {
+		return true, nil
+	}
+
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch dir {
+	case "temp":
+		return request.Status != domain.StatusProcessing, nil
+	case checkpointsDir:
+		return request.Status.HasResults(), nil
+	}
+	return false, nil
+}
+
+// finishedBefore lists every request in status that completed before cutoff
+func (s *CleanupService) finishedBefore(ctx context.Context, status domain.RequestStatus, cutoff time.Time) ([]*domain.Request, error) {
+	return listAll(ctx, s.requestRepo, ports.RequestFilter{Status: &status, CompletedBefore: &cutoff})
+}
+
+// remove deletes path unless running in dry-run mode, and reports whether it counts as reclaimed
+func (s *CleanupService) remove(path string) bool {
+	if s.policy.DryRun {
+		s.logger.Info("would remove directory", zap.String("path", path))
+		return true
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		s.logger.Warn("failed to remove directory", zap.String("path", path), zap.Error(err))
+		return false
+	}
+	s.logger.Debug("removed directory", zap.String("path", path))
+	return true
+}
+
+// dirUsage returns the total size of the files under path and the latest modification time found
+func dirUsage(path string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	return size, modified, err
+}
@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	// outboxLease hides a claimed task from other dispatchers while it is being enqueued
+	outboxLease = time.Minute
+
+	// outboxBaseBackoff is the delay after the first failed attempt, doubled on every failure
+	outboxBaseBackoff = time.Second
+)
+
+// OutboxDispatcher pushes the translation tasks recorded in the outbox to the queue
+type OutboxDispatcher struct {
+	outboxRepo  ports.OutboxRepository
+	requestRepo ports.RequestRepository
+	queueClient ports.QueueClient
+	cfg         config.OutboxConfig
+	logger      *zap.Logger
+	wake        chan struct{}
+}
+
+// NewOutboxDispatcher creates a new outbox dispatcher
+func NewOutboxDispatcher(
+	outboxRepo ports.OutboxRepository,
+	requestRepo ports.RequestRepository,
+	queueClient ports.QueueClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo:  outboxRepo,
+		requestRepo: requestRepo,
+		queueClient: queueClient,
+		cfg:         cfg.Outbox,
+		logger:      logger,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Run dispatches due tasks every poll interval, or as soon as Wake is called, until ctx is cancelled
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	d.logger.Info("outbox dispatcher started", zap.Duration("poll_interval", d.cfg.PollInterval))
+
+	for {
+		d.Dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Wake makes Run dispatch right away, e.g. after a request was created
+func (d *OutboxDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// A dispatch is already pending
+	}
+}
+
+// Dispatch enqueues every due task once and returns how many left the outbox
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) int {
+	dispatched := 0
+	for ctx.Err() == nil {
+		tasks, err := d.outboxRepo.Claim(ctx, d.cfg.BatchSize, outboxLease)
+		if err != nil {
+			d.logger.Error("failed to claim outbox tasks", zap.Error(err))
+			return dispatched
+		}
+
+		for _, task := range tasks {
+			if d.dispatch(ctx, task) {
+				dispatched++
+			}
+		}
+
+		if len(tasks) < d.cfg.BatchSize {
+			return dispatched
+		}
+	}
+	return dispatched
+}
+
+// dispatch enqueues one task and removes it from the outbox, or reschedules it with backoff.
+// Tasks of requests that were deleted or left the queue meanwhile are dropped.
+func (d *OutboxDispatcher) dispatch(ctx context.Context, task *domain.OutboxTask) bool {
+	logger := d.logger.With(
+		zap.String("request_id", task.RequestID.String()),
+		zap.Int("attempt", task.Attempts),
+	)
+
+	request, err := d.requestRepo.GetByID(ctx, task.RequestID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return d.remove(ctx, task, logger)
+	case err != nil:
+		d.reschedule(ctx, task, err, logger)
+		return false
+	case request.Status != domain.StatusQueued:
+		logger.Debug("dropping outbox task of a request that left the queue", zap.String("status", string(request.Status)))
+		return d.remove(ctx, task, logger)
+	}
+
+	err = d.queueClient.EnqueueTranslation(ctx, request.ID, task.FilePath, string(task.FileType), request.PageCount, request.Options, request.Priority)
+	if err != nil && !errors.Is(err, domain.ErrInvalidState) {
+		// ErrInvalidState means an earlier attempt enqueued the task but could not remove it
+		d.reschedule(ctx, task, err, logger)
+		return false
+	}
+
+	if task.Attempts > 1 {
+		logger.Info("outbox task enqueued after retries")
+	}
+	return d.remove(ctx, task, logger)
+}
+
+func (d *OutboxDispatcher) remove(ctx context.Context, task *domain.OutboxTask, logger *zap.Logger) bool {
+	if err := d.outboxRepo.Delete(ctx, task.ID); err != nil {
+		// The task is dispatched again once its lease expires
+		logger.Error("failed to remove outbox task", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+func (d *OutboxDispatcher) reschedule(ctx context.Context, task *domain.OutboxTask, cause error, logger *zap.Logger) {
+	delay := d.backoff(task.Attempts)
+	logger.Warn("failed to enqueue outbox task",
+		zap.Duration("retry_in", delay),
+		zap.Error(cause),
+	)
+
+	if err := d.outboxRepo.Reschedule(ctx, task.ID, delay, cause.Error()); err != nil {
+		logger.Error("failed to reschedule outbox task", zap.Error(err))
+	}
+}
+
+// backoff returns the delay before the next attempt of a task that failed attempts times
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
@@ -0,0 +1,187 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"go.uber.org/zap"
+)
+
+// ReconcileService brings queued, processing and retrying requests back in line with the queue
+// when their task was lost (e.g. a worker killed mid-run) or gave up
+type ReconcileService struct {
+	requestRepo    ports.RequestRepository
+	queueClient    ports.QueueClient
+	requestService *RequestService
+	staleAfter     time.Duration
+	logger         *zap.Logger
+}
+
+// NewReconcileService creates a new reconcile service
+func NewReconcileService(
+	requestRepo ports.RequestRepository,
+	queueClient ports.QueueClient,
+	requestService *RequestService,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *ReconcileService {
+	return &ReconcileService{
+		requestRepo:    requestRepo,
+		queueClient:    queueClient,
+		requestService: requestService,
+		staleAfter:     cfg.Reconcile.StaleAfter,
+		logger:         logger,
+	}
+}
+
+// Reconcile checks every queued, processing or retrying request not updated for the stale period
+// against its task. Requests whose task disappeared are enqueued again, those whose task
+// was archived or completed without finishing them are marked failed. In dry-run mode the
+// report lists the findings without acting on them.
+func (s *ReconcileService) Reconcile(ctx context.Context, dryRun bool) (*domain.ReconcileReport, error) {
+	report := &domain.ReconcileReport{DryRun: dryRun, Findings: []domain.ReconcileFinding{}}
+	cutoff := time.Now().Add(-s.staleAfter)
+
+	for _, status := range []domain.RequestStatus{domain.StatusQueued, domain.StatusProcessing, domain.StatusRetrying} {
+		requests, err := listAll(ctx, s.requestRepo, ports.RequestFilter{Status: &status, UpdatedBefore: &cutoff})
+		if err != nil {
+			return report, err
+		}
+
+		for _, request := range requests {
+			report.Checked++
+
+			finding, err := s.inspect(ctx, request)
+			if err != nil {
+				s.logger.Warn("failed to inspect task",
+					zap.String("request_id", request.ID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if finding == nil {
+				continue
+			}
+
+			if !dryRun {
+				if err := s.apply(ctx, request, finding); err != nil {
+					finding.Error = err.Error()
+				}
+			}
+
+			s.logger.Warn("reconciled request",
+				zap.String("request_id", request.ID.String()),
+				zap.String("status", string(finding.Status)),
+				zap.String("task_state", string(finding.TaskState)),
+				zap.String("action", string(finding.Action)),
+				zap.String("reason", finding.Reason),
+				zap.String("error", finding.Error),
+				zap.Bool("dry_run", dryRun),
+			)
+			report.Findings = append(report.Findings, *finding)
+		}
+	}
+
+	s.logger.Info("reconcile finished",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("checked", report.Checked),
+		zap.Int("findings", len(report.Findings)),
+	)
+
+	return report, nil
+}
+
+// inspect returns what to do about a request, or nil while its task is still in the queue.
+// Active tasks of a dead worker are not reported: asynq moves them to retry once their lease expires.
+func (s *ReconcileService) inspect(ctx context.Context, request *domain.Request) (*domain.ReconcileFinding, error) {
+	task, err := s.queueClient.TranslationTask(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	finding := &domain.ReconcileFinding{
+		RequestID: request.ID,
+		Status:    request.Status,
+		TaskState: domain.TaskStateMissing,
+	}
+	if task != nil {
+		finding.TaskState = task.State
+	}
+
+	// The task of a request that finished since it was listed is gone or completed as well
+	if finding.TaskState == domain.TaskStateMissing || finding.TaskState == domain.TaskStateCompleted {
+		current, err := s.requestRepo.GetByID(ctx, request.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != request.Status {
+			return nil, nil
+		}
+	}
+
+	switch finding.TaskState {
+	case domain.TaskStateMissing:
+		if _, err := s.requestService.inputPath(request); err != nil {
+			finding.Action = domain.ReconcileFailed
+			finding.Reason = "translation task was lost and the upload is no longer available"
+		} else {
+			finding.Action = domain.ReconcileRequeued
+			finding.Reason = "translation task was lost"
+		}
+	case domain.TaskStateArchived:
+		finding.Action = domain.ReconcileFailed
+		finding.Reason = fmt.Sprintf("translation task gave up after %d retries: %s", task.Retried, task.LastError)
+	case domain.TaskStateCompleted:
+		finding.Action = domain.ReconcileFailed
+		finding.Reason = "translation task finished without completing the request"
+	default:
+		return nil, nil
+	}
+
+	return finding, nil
+}
+
+// apply carries out a finding. Status changes are conditional, so a request that moved on
+// since it was inspected is left alone and the finding reports domain.ErrInvalidState.
+func (s *ReconcileService) apply(ctx context.Context, request *domain.Request, finding *domain.ReconcileFinding) error {
+	switch finding.Action {
+	case domain.ReconcileRequeued:
+		if request.Status != domain.StatusQueued {
+			err := s.requestRepo.TransitionStatus(ctx, request.ID,
+				[]domain.RequestStatus{request.Status}, domain.StatusQueued)
+			if err != nil {
+				return err
+			}
+		}
+
+		inputPath, err := s.requestService.inputPath(request)
+		if err != nil {
+			return err
+		}
+
+		jobType := request.FileType.WorkerFileType()
+		err = s.queueClient.EnqueueTranslation(ctx, request.ID, inputPath, string(jobType), request.PageCount, request.Options, request.Priority)
+		if err != nil && !errors.Is(err, domain.ErrInvalidState) {
+			// ErrInvalidState means the task showed up again meanwhile
+			return err
+		}
+
+	case domain.ReconcileFailed:
+		if err := s.requestRepo.Fail(ctx, request.ID, finding.Reason); err != nil {
+			return err
+		}
+
+		s.requestService.publish(ctx, ports.ProgressUpdate{
+			RequestID: request.ID,
+			Status:    string(domain.StatusFailed),
+			Message:   fmt.Sprintf("Translation failed: %s", finding.Reason),
+		})
+	}
+
+	return nil
+}
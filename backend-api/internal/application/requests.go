@@ -0,0 +1,388 @@
+// Package application holds the use cases that coordinate several ports,
+// such as the lifecycle operations on an existing translation request.
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pageArchiveName is the ZIP built on upload from several images or an ingested archive.
+// When present it is what the worker translates instead of the uploaded file.
+const pageArchiveName = "pages.zip"
+
+// translatedArchiveSuffix is appended by the Python worker to the name of a translated ZIP
+const translatedArchiveSuffix = "_translated.zip"
+
+// checkpointsDir holds the pages the worker already translated, so a new attempt resumes from them
+const checkpointsDir = "checkpoints"
+
+// storageDirs are the directories under the storage path that hold per-request subdirectories
+var storageDirs = []string{"uploads", "originals", "translated", "temp", checkpointsDir}
+
+// listPageSize is the number of requests fetched per query when walking every matching request
+const listPageSize = 100
+
+// RequestService manages translation requests after they were uploaded
+type RequestService struct {
+	requestRepo ports.RequestRepository
+	resultRepo  ports.ResultRepository
+	queueClient ports.QueueClient
+	dispatcher  *OutboxDispatcher
+	publisher   ports.ProgressPublisher
+	storagePath string
+	logger      *zap.Logger
+}
+
+// NewRequestService creates a new request service
+func NewRequestService(
+	requestRepo ports.RequestRepository,
+	resultRepo ports.ResultRepository,
+	queueClient ports.QueueClient,
+	dispatcher *OutboxDispatcher,
+	publisher ports.ProgressPublisher,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *RequestService {
+	return &RequestService{
+		requestRepo: requestRepo,
+		resultRepo:  resultRepo,
+		queueClient: queueClient,
+		dispatcher:  dispatcher,
+		publisher:   publisher,
+		storagePath: cfg.Storage.Path,
+		logger:      logger,
+	}
+}
+
+// Cancel stops a queued, processing or retrying request.
+// The status is changed first so a worker that misses the cancel signal still stops at its next
+// progress update. Returns domain.ErrInvalidState if the request already finished.
+func (s *RequestService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Request, error) {
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !request.CanCancel() {
+		return nil, fmt.Errorf("request is %s: %w", request.Status, domain.ErrInvalidState)
+	}
+
+	err = s.requestRepo.TransitionStatus(ctx, id,
+		[]domain.RequestStatus{domain.StatusQueued, domain.StatusProcessing, domain.StatusRetrying}, domain.StatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.queueClient.CancelTranslation(ctx, id); err != nil {
+		// The worker notices the cancelled status on its next progress update
+		s.logger.Warn("failed to cancel task",
+			zap.String("request_id", id.String()),
+			zap.Error(err),
+		)
+	}
+
+	s.publish(ctx, ports.ProgressUpdate{
+		RequestID: id,
+		Status:    string(domain.StatusCancelled),
+		Progress:  request.Progress,
+		Message:   "Translation cancelled",
+	})
+
+	s.logger.Info("request cancelled",
+		zap.String("request_id", id.String()),
+		zap.String("previous_status", string(request.Status)),
+	)
+
+	return s.requestRepo.GetByID(ctx, id)
+}
+
+// Retry runs a completed, partial, failed or cancelled request again from its original upload.
+// Previous results and translated files are removed first. Pages checkpointed by an unfinished
+// run are reused unless options change them. A non-nil options replaces
+// the request's translation options. Returns domain.ErrInvalidState while the request is
+// queued, processing or retrying, and domain.ErrUploadGone if the upload was cleaned up.
+func (s *RequestService) Retry(ctx context.Context, id uuid.UUID, options *domain.TranslationOptions) (*domain.Request, error) {
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !request.IsCompleted() {
+		return nil, fmt.Errorf("request is %s: %w", request.Status, domain.ErrInvalidState)
+	}
+
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	inputPath, err := s.inputPath(request)
+	if err != nil {
+		return nil, err
+	}
+
+	from := request.Status
+	if err := request.Reset(); err != nil {
+		return nil, err
+	}
+	// Checkpointed pages were translated with the previous options
+	dropCheckpoints := options != nil && *options != request.Options
+	if options != nil {
+		request.Options = *options
+	}
+
+	// The reset and the outbox task are saved together, so the request cannot be left
+	// queued without a task; the status check stops concurrent retries from enqueueing it twice
+	err = s.requestRepo.RequeueWithTask(ctx, request, []domain.RequestStatus{from}, domain.NewOutboxTask(request, inputPath))
+	if err != nil {
+		return nil, err
+	}
+	s.removeOutputs(request, inputPath, dropCheckpoints)
+
+	// Enqueue right away instead of waiting for the next poll
+	if s.dispatcher != nil {
+		s.dispatcher.Wake()
+	}
+
+	s.logger.Info("request retried",
+		zap.String("request_id", id.String()),
+		zap.String("input_path", inputPath),
+	)
+
+	return request, nil
+}
+
+// RetranslatePage enqueues the re-translation of one page of a completed request.
+// A non-nil options replaces the request's translation options for this page only.
+// Returns domain.ErrInvalidState unless the request completed (fully or partially) or while the page is already
+// being re-translated, domain.ErrNotFound for an unknown page and domain.ErrUploadGone if
+// the original page was removed.
+func (s *RequestService) RetranslatePage(
+	ctx context.Context,
+	id uuid.UUID,
+	pageNumber int,
+	options *domain.TranslationOptions,
+) (*domain.Result, error) {
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !request.Status.HasResults() {
+		return nil, fmt.Errorf("request is %s: %w", request.Status, domain.ErrInvalidState)
+	}
+
+	result, err := s.resultRepo.GetByPage(ctx, id, pageNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	originalRel, ok := domain.FileRelPath(id, "originals", result.OriginalPath)
+	if !ok {
+		return nil, domain.ErrUploadGone
+	}
+	originalPath := filepath.Join(s.storagePath, "originals", id.String(), filepath.FromSlash(originalRel))
+	if _, err := os.Stat(originalPath); err != nil {
+		return nil, domain.ErrUploadGone
+	}
+
+	pageOptions := request.Options
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		pageOptions = *options
+	}
+
+	if err := s.queueClient.EnqueuePageTranslation(ctx, id, pageNumber, pageOptions, request.Priority); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("page re-translation requested",
+		zap.String("request_id", id.String()),
+		zap.Int("page", pageNumber),
+	)
+
+	return result, nil
+}
+
+// SetPriority moves a queued request to the queue of another priority.
+// Returns domain.ErrInvalidState once the request left the queue.
+func (s *RequestService) SetPriority(ctx context.Context, id uuid.UUID, priority domain.Priority) (*domain.Request, error) {
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != domain.StatusQueued {
+		return nil, fmt.Errorf("request is %s: %w", request.Status, domain.ErrInvalidState)
+	}
+	if request.Priority == priority {
+		return request, nil
+	}
+
+	if err := s.queueClient.MoveTranslation(ctx, id, priority); err != nil {
+		return nil, err
+	}
+	if err := s.requestRepo.SetPriority(ctx, id, priority); err != nil {
+		// Move the task back so its queue keeps matching the stored priority
+		if moveErr := s.queueClient.MoveTranslation(context.WithoutCancel(ctx), id, request.Priority); moveErr != nil {
+			s.logger.Error("failed to move task back after priority update failed",
+				zap.String("request_id", id.String()),
+				zap.Error(moveErr),
+			)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("request priority changed",
+		zap.String("request_id", id.String()),
+		zap.String("from", string(request.Priority)),
+		zap.String("to", string(priority)),
+	)
+
+	return s.requestRepo.GetByID(ctx, id)
+}
+
+// Delete removes a request, its results, its task and every file stored for it.
+// A processing request is only deleted when force is set, in which case its task is
+// cancelled first. Returns domain.ErrInvalidState if the request is processing without force.
+func (s *RequestService) Delete(ctx context.Context, id uuid.UUID, force bool) error {
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !request.IsCompleted() {
+		// Stop the request first so a worker picking it up meanwhile skips it
+		// A retrying request has no attempt running, its pending retry is deleted below
+		from := []domain.RequestStatus{domain.StatusQueued, domain.StatusRetrying}
+		if force {
+			from = append(from, domain.StatusProcessing)
+		}
+		if err := s.requestRepo.TransitionStatus(ctx, id, from, domain.StatusCancelled); err != nil {
+			return err
+		}
+	}
+
+	if err := s.queueClient.DeleteTranslation(ctx, id); err != nil {
+		// A leftover task is skipped by the worker once the request is gone
+		s.logger.Warn("failed to delete task",
+			zap.String("request_id", id.String()),
+			zap.Error(err),
+		)
+	}
+
+	if err := s.requestRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeStorage(id)
+
+	if !request.IsCompleted() {
+		s.publish(ctx, ports.ProgressUpdate{
+			RequestID: id,
+			Status:    string(domain.StatusCancelled),
+			Progress:  request.Progress,
+			Message:   "Request deleted",
+		})
+	}
+
+	s.logger.Info("request deleted",
+		zap.String("request_id", id.String()),
+		zap.String("previous_status", string(request.Status)),
+	)
+
+	return nil
+}
+
+// inputPath locates the file that was enqueued for a request when it was uploaded
+func (s *RequestService) inputPath(request *domain.Request) (string, error) {
+	uploadDir := filepath.Join(s.storagePath, "uploads", request.ID.String())
+
+	candidates := []string{
+		filepath.Join(uploadDir, pageArchiveName),
+		filepath.Join(uploadDir, filepath.Base(request.Filename)),
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, nil
+		}
+	}
+	return "", domain.ErrUploadGone
+}
+
+// removeOutputs deletes the files written by a previous run of a request, and its
+// checkpointed pages when checkpoints is set
+func (s *RequestService) removeOutputs(request *domain.Request, inputPath string, checkpoints bool) {
+	paths := []string{
+		filepath.Join(s.storagePath, "originals", request.ID.String()),
+		filepath.Join(s.storagePath, "translated", request.ID.String()),
+		strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + translatedArchiveSuffix,
+	}
+	if checkpoints {
+		paths = append(paths, filepath.Join(s.storagePath, checkpointsDir, request.ID.String()))
+	}
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			s.logger.Warn("failed to remove previous output",
+				zap.String("request_id", request.ID.String()),
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// removeStorage deletes every directory stored for a request
+func (s *RequestService) removeStorage(id uuid.UUID) {
+	for _, dir := range storageDirs {
+		path := filepath.Join(s.storagePath, dir, id.String())
+		if err := os.RemoveAll(path); err != nil {
+			s.logger.Warn("failed to remove request files",
+				zap.String("request_id", id.String()),
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (s *RequestService) publish(ctx context.Context, update ports.ProgressUpdate) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.PublishProgress(ctx, update); err != nil {
+		s.logger.Error("failed to publish progress", zap.Error(err))
+	}
+}
+
+// listAll collects every request matching filter, one page at a time
+func listAll(ctx context.Context, requestRepo ports.RequestRepository, filter ports.RequestFilter) ([]*domain.Request, error) {
+	filter.Limit = listPageSize
+	filter.Offset = 0
+
+	// Collect everything first, acting while paging would shift the offsets
+	var requests []*domain.Request
+	for {
+		page, _, err := requestRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, page...)
+		if len(page) < listPageSize {
+			return requests, nil
+		}
+		filter.Offset += listPageSize
+	}
+}
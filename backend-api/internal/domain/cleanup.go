@@ -0,0 +1,10 @@
+package domain
+
+// CleanupReport summarises one run of the storage retention policies
+type CleanupReport struct {
+	DryRun         bool  `json:"dryRun"`
+	UploadsRemoved int   `json:"uploadsRemoved"` // upload directories of completed requests
+	RequestsPurged int   `json:"requestsPurged"` // failed and cancelled requests deleted with their files
+	OrphansRemoved int   `json:"orphansRemoved"` // directories without a request and stale temp directories
+	BytesReclaimed int64 `json:"bytesReclaimed"` // freed, or that would be freed in dry-run mode
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrNotFound is returned when a resource is not found
@@ -15,6 +19,9 @@ var (
 	// ErrWorkerFailed is returned when the translation worker fails
 	ErrWorkerFailed = errors.New("translation worker failed")
 
+	// ErrWorkerTimeout is returned when a translation job is stopped for taking too long
+	ErrWorkerTimeout = errors.New("translation worker timed out")
+
 	// ErrDatabaseError is returned when a database operation fails
 	ErrDatabaseError = errors.New("database operation failed")
 
@@ -29,8 +36,107 @@ var (
 
 	// ErrTooManyFiles is returned when too many files are uploaded
 	ErrTooManyFiles = errors.New("too many files")
+
+	// ErrInvalidState is returned when an operation is not allowed in the request's current status
+	ErrInvalidState = errors.New("invalid request state")
+
+	// ErrUploadGone is returned when the original upload of a request is no longer in storage
+	ErrUploadGone = errors.New("original upload no longer available")
+
+	// ErrInvalidArchive is returned when an uploaded archive or document cannot be read
+	ErrInvalidArchive = errors.New("invalid archive")
+
+	// ErrIdempotencyKeyTaken is returned when another upload with the same Idempotency-Key
+	// already created the request
+	ErrIdempotencyKeyTaken = errors.New("idempotency key already used")
+)
+
+// Error codes reported when an uploaded archive or document is rejected
+const (
+	CodeUnsupportedFormat       = "UNSUPPORTED_FORMAT"
+	CodeInvalidArchive          = "INVALID_ARCHIVE"
+	CodeNoPages                 = "NO_PAGES"
+	CodeArchivePathEscape       = "ARCHIVE_PATH_ESCAPE"
+	CodeArchiveSymlink          = "ARCHIVE_SYMLINK"
+	CodeArchiveUnsupportedEntry = "ARCHIVE_UNSUPPORTED_ENTRY"
+	CodeArchiveTooManyEntries   = "ARCHIVE_TOO_MANY_ENTRIES"
+	CodeArchiveTooLarge         = "ARCHIVE_TOO_LARGE"
+	CodeArchiveCompressionRatio = "ARCHIVE_COMPRESSION_RATIO"
+)
+
+// CodeInvalidOptions is reported when translation options are out of range
+const CodeInvalidOptions = "INVALID_OPTIONS"
+
+// CodeInvalidPriority is reported for an unknown request priority
+const CodeInvalidPriority = "INVALID_PRIORITY"
+
+// CodeIdempotencyKeyReused is reported when an Idempotency-Key is sent again with a different upload
+const CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+
+// Error codes reported by the translation worker with a WorkerError
+const (
+	WorkerCodeInputNotFound = "input_not_found"
+	WorkerCodeInvalidInput  = "invalid_input"
+	WorkerCodeModelMissing  = "model_missing"
+	WorkerCodeFontMissing   = "font_missing"
+	WorkerCodeInternal      = "internal"
+	WorkerCodeOutOfMemory   = "out_of_memory" // the worker ran out of memory or was OOM-killed
+	WorkerCodeCPULimit      = "cpu_limit"     // the worker used up its CPU time limit
+	WorkerCodeKilled        = "killed"        // the worker was killed by a signal the backend did not send
+)
+
+// WorkerError is the error the translation worker reported before it stopped.
+// It matches ErrWorkerFailed with errors.Is.
+type WorkerError struct {
+	Code    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *WorkerError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Unwrap returns ErrWorkerFailed
+func (e *WorkerError) Unwrap() error {
+	return ErrWorkerFailed
+}
+
+// Retryable reports whether another attempt with the same input may succeed
+func (e *WorkerError) Retryable() bool {
+	switch e.Code {
+	case WorkerCodeInputNotFound, WorkerCodeInvalidInput, WorkerCodeFontMissing, WorkerCodeCPULimit:
+		return false
+	}
+	return true
+}
+
+// Reasons a translation job is stopped with a WorkerTimeoutError
+const (
+	TimeoutReasonDeadline = "deadline" // the whole job ran past its deadline
+	TimeoutReasonStalled  = "stalled"  // the worker reported no progress for too long
 )
 
+// WorkerTimeoutError is the error of a translation job stopped for taking too long.
+// It matches ErrWorkerTimeout with errors.Is.
+type WorkerTimeoutError struct {
+	Reason string
+	Limit  time.Duration // the deadline or stall interval that was exceeded
+}
+
+// Error implements the error interface
+func (e *WorkerTimeoutError) Error() string {
+	if e.Reason == TimeoutReasonStalled {
+		return fmt.Sprintf("worker stalled: no progress for %s", e.Limit)
+	}
+	return fmt.Sprintf("worker timed out: job exceeded its %s deadline", e.Limit)
+}
+
+// Unwrap returns ErrWorkerTimeout
+func (e *WorkerTimeoutError) Unwrap() error {
+	return ErrWorkerTimeout
+}
+
 // AppError represents an application error with additional context
 type AppError struct {
 	Code    string
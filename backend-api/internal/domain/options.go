@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SourceLanguages lists the language codes the worker can read pages in.
+// Its OCR model, manga-ocr, only reads Japanese.
+var SourceLanguages = map[string]string{
+	"ja": "Japanese",
+}
+
+// TargetLanguages lists the language codes the worker can translate to
+var TargetLanguages = map[string]string{
+	"ja": "Japanese",
+	"zh": "Chinese",
+	"ko": "Korean",
+	"en": "English",
+	"fr": "French",
+	"es": "Spanish",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+}
+
+// fontExts lists the font formats the typesetter can load
+var fontExts = map[string]bool{
+	".ttf": true,
+	".otf": true,
+}
+
+// TranslationOptions are per-request overrides of the worker defaults.
+// Zero values mean "use the worker default".
+type TranslationOptions struct {
+	SourceLang          string  `json:"sourceLang,omitempty"`          // language code, e.g. "ja"
+	TargetLang          string  `json:"targetLang,omitempty"`          // language code, e.g. "en"
+	Font                string  `json:"font,omitempty"`                // font file name in ai-worker/fonts
+	DetectionConfidence float64 `json:"detectionConfidence,omitempty"` // text box detection threshold, (0, 1]
+	OutputQuality       int     `json:"outputQuality,omitempty"`       // JPEG quality, 1-100
+}
+
+// Validate checks that every set option is within the range the worker accepts
+func (o TranslationOptions) Validate() error {
+	if o.SourceLang != "" {
+		if _, ok := SourceLanguages[o.SourceLang]; !ok {
+			return invalidOption(fmt.Sprintf("unsupported source language %q", o.SourceLang))
+		}
+	}
+	if o.TargetLang != "" {
+		if _, ok := TargetLanguages[o.TargetLang]; !ok {
+			return invalidOption(fmt.Sprintf("unsupported target language %q", o.TargetLang))
+		}
+	}
+	if o.SourceLang != "" && o.SourceLang == o.TargetLang {
+		return invalidOption("source and target language must differ")
+	}
+	if o.Font != "" {
+		if path.Base(o.Font) != o.Font || strings.ContainsAny(o.Font, `/\`) || !fontExts[strings.ToLower(path.Ext(o.Font))] {
+			return invalidOption(fmt.Sprintf("invalid font %q (expected a .ttf or .otf file name)", o.Font))
+		}
+	}
+	if o.DetectionConfidence < 0 || o.DetectionConfidence > 1 {
+		return invalidOption("detection confidence must be between 0 and 1")
+	}
+	if o.OutputQuality < 0 || o.OutputQuality > 100 {
+		return invalidOption("output quality must be between 1 and 100")
+	}
+	return nil
+}
+
+func invalidOption(message string) error {
+	return NewAppError(CodeInvalidOptions, message, ErrInvalidInput)
+}
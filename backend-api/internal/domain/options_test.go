@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTranslationOptionsValidateLanguages(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TranslationOptions
+		valid   bool
+	}{
+		{"defaults", TranslationOptions{}, true},
+		{"japanese to french", TranslationOptions{SourceLang: "ja", TargetLang: "fr"}, true},
+		{"target only", TranslationOptions{TargetLang: "ko"}, true},
+		// The OCR only reads Japanese
+		{"chinese source", TranslationOptions{SourceLang: "zh", TargetLang: "en"}, false},
+		{"korean source", TranslationOptions{SourceLang: "ko"}, false},
+		{"unknown target", TranslationOptions{TargetLang: "xx"}, false},
+		{"same language", TranslationOptions{SourceLang: "ja", TargetLang: "ja"}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.options.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: got %v, want no error", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, ErrInvalidInput)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxTask is a translation task recorded in the same transaction as its request.
+// The outbox dispatcher pushes it to the queue, retrying with backoff until it succeeds,
+// so a saved request always ends up with a queued task.
+type OutboxTask struct {
+	ID            int64
+	RequestID     uuid.UUID
+	FilePath      string
+	FileType      FileType // format handed to the worker, see FileType.WorkerFileType
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// NewOutboxTask creates the task enqueueing the translation of a new request
+func NewOutboxTask(request *Request, filePath string) *OutboxTask {
+	return &OutboxTask{
+		RequestID: request.ID,
+		FilePath:  filePath,
+		FileType:  request.FileType.WorkerFileType(),
+	}
+}
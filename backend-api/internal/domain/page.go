@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PageStatus represents how far the worker got with one page of a request
+type PageStatus string
+
+const (
+	PageStatusPending    PageStatus = "pending"
+	PageStatusProcessing PageStatus = "processing"
+	PageStatusDone       PageStatus = "done"
+	PageStatusSkipped    PageStatus = "skipped" // the worker could not read the page
+	PageStatusFailed     PageStatus = "failed"
+)
+
+// IsFinal reports whether the worker is done with a page in status s, successfully or not
+func (s PageStatus) IsFinal() bool {
+	return s == PageStatusDone || s == PageStatusSkipped || s == PageStatusFailed
+}
+
+// Page tracks the translation of one page of a request
+type Page struct {
+	RequestID    uuid.UUID  `json:"-"`
+	PageNumber   int        `json:"pageNumber"`
+	Name         string     `json:"name"` // slash-separated path of the page inside the upload
+	Status       PageStatus `json:"status"`
+	ErrorMessage *string    `json:"error,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// NewPage creates a pending page
+func NewPage(requestID uuid.UUID, pageNumber int, name string) *Page {
+	return &Page{
+		RequestID:  requestID,
+		PageNumber: pageNumber,
+		Name:       name,
+		Status:     PageStatusPending,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// PageSummary counts the pages of a request by outcome
+type PageSummary struct {
+	Total   int
+	Done    int
+	Skipped int
+	Failed  int
+}
+
+// SummarizePages counts pages by status. Pages the worker never finished count as failed.
+func SummarizePages(pages []*Page) PageSummary {
+	summary := PageSummary{Total: len(pages)}
+	for _, page := range pages {
+		switch page.Status {
+		case PageStatusDone:
+			summary.Done++
+		case PageStatusSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// CompletionStatus returns the status a request finishes with: completed when every page
+// was translated, partial when only some were and failed when none was
+func (s PageSummary) CompletionStatus() RequestStatus {
+	switch {
+	case s.Done == s.Total:
+		return StatusCompleted
+	case s.Done > 0:
+		return StatusPartial
+	default:
+		return StatusFailed
+	}
+}
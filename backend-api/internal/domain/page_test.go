@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPageSummaryCompletionStatus(t *testing.T) {
+	requestID := uuid.New()
+	pagesWith := func(statuses ...PageStatus) []*Page {
+		pages := make([]*Page, 0, len(statuses))
+		for i, status := range statuses {
+			page := NewPage(requestID, i+1, "page.jpg")
+			page.Status = status
+			pages = append(pages, page)
+		}
+		return pages
+	}
+
+	tests := []struct {
+		name  string
+		pages []*Page
+		want  RequestStatus
+	}{
+		{"all done", pagesWith(PageStatusDone, PageStatusDone), StatusCompleted},
+		{"no pages tracked", pagesWith(), StatusCompleted},
+		{"one skipped", pagesWith(PageStatusDone, PageStatusSkipped), StatusPartial},
+		{"one failed", pagesWith(PageStatusFailed, PageStatusDone), StatusPartial},
+		// A page the worker never finished did not get translated either
+		{"one unfinished", pagesWith(PageStatusDone, PageStatusProcessing), StatusPartial},
+		{"none done", pagesWith(PageStatusSkipped, PageStatusFailed), StatusFailed},
+	}
+
+	for _, tt := range tests {
+		if got := SummarizePages(tt.pages).CompletionStatus(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
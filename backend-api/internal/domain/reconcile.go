@@ -0,0 +1,50 @@
+package domain
+
+import "github.com/google/uuid"
+
+// TaskState is the state of a request's task in the queue
+type TaskState string
+
+const (
+	TaskStatePending   TaskState = "pending"
+	TaskStateScheduled TaskState = "scheduled"
+	TaskStateActive    TaskState = "active"
+	TaskStateRetry     TaskState = "retry"
+	TaskStateArchived  TaskState = "archived"
+	TaskStateCompleted TaskState = "completed"
+	TaskStateMissing   TaskState = "missing" // the request has no task in any queue
+)
+
+// TaskInfo describes the queued task of a request
+type TaskInfo struct {
+	State     TaskState
+	Queue     string
+	Retried   int
+	MaxRetry  int
+	LastError string
+}
+
+// ReconcileAction is what the reconciler did, or would do in dry-run mode, about a request
+type ReconcileAction string
+
+const (
+	ReconcileRequeued ReconcileAction = "requeued" // the translation task was enqueued again
+	ReconcileFailed   ReconcileAction = "failed"   // the request was marked failed
+)
+
+// ReconcileFinding is a request whose status disagrees with the queue
+type ReconcileFinding struct {
+	RequestID uuid.UUID       `json:"requestId"`
+	Status    RequestStatus   `json:"status"`
+	TaskState TaskState       `json:"taskState"`
+	Action    ReconcileAction `json:"action"`
+	Reason    string          `json:"reason"`
+	Error     string          `json:"error,omitempty"` // set if the action could not be applied
+}
+
+// ReconcileReport summarises a reconciler run
+type ReconcileReport struct {
+	DryRun   bool               `json:"dryRun"`
+	Checked  int                `json:"checked"`
+	Findings []ReconcileFinding `json:"findings"`
+}
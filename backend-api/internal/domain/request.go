@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,31 +14,115 @@ type RequestStatus string
 const (
 	StatusQueued     RequestStatus = "queued"
 	StatusProcessing RequestStatus = "processing"
+	StatusRetrying   RequestStatus = "retrying" // an attempt failed, the task waits for its next attempt
 	StatusCompleted  RequestStatus = "completed"
+	StatusPartial    RequestStatus = "partial" // completed, but some pages were skipped or failed
 	StatusFailed     RequestStatus = "failed"
+	StatusCancelled  RequestStatus = "cancelled"
 )
 
+// Priority decides how soon a request is picked up relative to the others
+type Priority string
+
+const (
+	PriorityUrgent Priority = "urgent"
+	PriorityNormal Priority = "normal"
+	PriorityBulk   Priority = "bulk"
+)
+
+// ParsePriority parses a priority name; an empty value is normal priority
+func ParsePriority(value string) (Priority, error) {
+	switch priority := Priority(strings.ToLower(strings.TrimSpace(value))); priority {
+	case "":
+		return PriorityNormal, nil
+	case PriorityUrgent, PriorityNormal, PriorityBulk:
+		return priority, nil
+	default:
+		return "", NewAppError(CodeInvalidPriority,
+			fmt.Sprintf("invalid priority %q (expected urgent, normal or bulk)", value), ErrInvalidInput)
+	}
+}
+
 // FileType represents the type of uploaded file
 type FileType string
 
 const (
 	FileTypeImage FileType = "image"
 	FileTypeZip   FileType = "zip"
+	FileTypeCBZ   FileType = "cbz"
+	FileTypeRAR   FileType = "rar" // .rar and .cbr
+	FileType7z    FileType = "7z"  // .7z and .cb7
+	FileTypePDF   FileType = "pdf"
+	FileTypeEPUB  FileType = "epub"
 )
 
+// fileTypesByExt maps upload extensions to their file type
+var fileTypesByExt = map[string]FileType{
+	".jpg":  FileTypeImage,
+	".jpeg": FileTypeImage,
+	".png":  FileTypeImage,
+	".webp": FileTypeImage,
+	".zip":  FileTypeZip,
+	".cbz":  FileTypeCBZ,
+	".rar":  FileTypeRAR,
+	".cbr":  FileTypeRAR,
+	".7z":   FileType7z,
+	".cb7":  FileType7z,
+	".pdf":  FileTypePDF,
+	".epub": FileTypeEPUB,
+}
+
+// FileTypeFromExt returns the file type for a lower-case extension (including the dot)
+func FileTypeFromExt(ext string) (FileType, bool) {
+	fileType, ok := fileTypesByExt[ext]
+	return fileType, ok
+}
+
+// IsArchive returns true if the file holds several pages
+func (t FileType) IsArchive() bool {
+	return t != FileTypeImage
+}
+
+// WorkerFileType returns the format handed to the worker, which only reads single images
+// and ZIP archives; other archives are ingested into a ZIP on upload
+func (t FileType) WorkerFileType() FileType {
+	if t.IsArchive() {
+		return FileTypeZip
+	}
+	return FileTypeImage
+}
+
+// NeedsIngestion returns true if the upload must be converted to a ZIP of pages before translation
+func (t FileType) NeedsIngestion() bool {
+	return t.IsArchive() && t != FileTypeZip
+}
+
 // Request represents a translation request
 type Request struct {
-	ID            uuid.UUID     `json:"id"`
-	Filename      string        `json:"filename"`
-	FileType      FileType      `json:"fileType"`
-	Status        RequestStatus `json:"status"`
-	Progress      int           `json:"progress"`
-	PageCount     int           `json:"pageCount"`
-	ThumbnailPath *string       `json:"thumbnail,omitempty"`
-	ErrorMessage  *string       `json:"errorMessage,omitempty"`
-	CreatedAt     time.Time     `json:"createdAt"`
-	UpdatedAt     time.Time     `json:"updatedAt"`
-	CompletedAt   *time.Time    `json:"completedAt,omitempty"`
+	ID            uuid.UUID          `json:"id"`
+	Filename      string             `json:"filename"`
+	FileType      FileType           `json:"fileType"`
+	Status        RequestStatus      `json:"status"`
+	Priority      Priority           `json:"priority"`
+	Progress      int                `json:"progress"`
+	PageCount     int                `json:"pageCount"`
+	ThumbnailPath *string            `json:"thumbnail,omitempty"`
+	ErrorMessage  *string            `json:"errorMessage,omitempty"`
+	Options       TranslationOptions `json:"options"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+	CompletedAt   *time.Time         `json:"completedAt,omitempty"`
+
+	// Attempt being run out of MaxAttempts, and when a retrying request runs again
+	Attempt       int        `json:"attempt"`
+	MaxAttempts   int        `json:"maxAttempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+
+	// Idempotency-Key of the upload that created the request, a fingerprint of its body and
+	// the position of the request among those the upload created
+	IdempotencyKey  *string `json:"-"`
+	IdempotencyHash *string `json:"-"`
+	IdempotencySeq  *int    `json:"-"`
 }
 
 // NewRequest creates a new translation request
@@ -47,6 +133,7 @@ func NewRequest(filename string, fileType FileType) *Request {
 		Filename:  filename,
 		FileType:  fileType,
 		Status:    StatusQueued,
+		Priority:  PriorityNormal,
 		Progress:  0,
 		PageCount: 0,
 		CreatedAt: now,
@@ -54,28 +141,64 @@ func NewRequest(filename string, fileType FileType) *Request {
 	}
 }
 
-// UpdateStatus updates the request status and progress
-func (r *Request) UpdateStatus(status RequestStatus, progress int) {
+// UpdateStatus updates the request status and progress.
+// Returns a *TransitionError if the current status cannot move to status.
+func (r *Request) UpdateStatus(status RequestStatus, progress int) error {
+	if err := ValidateTransition(r.Status, status); err != nil {
+		return err
+	}
+
 	r.Status = status
 	r.Progress = progress
 	r.UpdatedAt = time.Now()
 
-	if status == StatusCompleted || status == StatusFailed {
+	if status.IsTerminal() {
 		now := time.Now()
 		r.CompletedAt = &now
+	} else {
+		r.CompletedAt = nil
 	}
+	return nil
 }
 
-// SetError sets the error message and marks the request as failed
-func (r *Request) SetError(message string) {
+// SetError sets the error message and marks the request as failed.
+// Returns a *TransitionError if the request can no longer fail.
+func (r *Request) SetError(message string) error {
+	if err := ValidateTransition(r.Status, StatusFailed); err != nil {
+		return err
+	}
+
 	r.Status = StatusFailed
 	r.ErrorMessage = &message
+	r.NextAttemptAt = nil
 	r.UpdatedAt = time.Now()
 	now := time.Now()
 	r.CompletedAt = &now
+	return nil
 }
 
-// IsCompleted returns true if the request is completed or failed
+// IsCompleted returns true if the request is completed, failed or cancelled
 func (r *Request) IsCompleted() bool {
-	return r.Status == StatusCompleted || r.Status == StatusFailed
+	return r.Status.IsTerminal()
+}
+
+// Reset puts a finished request back in the queue for another run.
+// Returns a *TransitionError if the request cannot be queued again.
+func (r *Request) Reset() error {
+	if err := ValidateTransition(r.Status, StatusQueued); err != nil {
+		return err
+	}
+
+	r.Status = StatusQueued
+	r.Progress = 0
+	r.ErrorMessage = nil
+	r.CompletedAt = nil
+	r.NextAttemptAt = nil
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// CanCancel returns true if the request is still waiting for or running its translation
+func (r *Request) CanCancel() bool {
+	return r.Status == StatusQueued || r.Status == StatusProcessing || r.Status == StatusRetrying
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +15,7 @@ type Result struct {
 	PageNumber     int       `json:"pageNumber"`
 	OriginalPath   string    `json:"original"`
 	TranslatedPath string    `json:"translated"`
+	Missing        bool      `json:"missing"` // the worker produced no translation for this page
 	CreatedAt      time.Time `json:"createdAt"`
 }
 
@@ -28,6 +31,26 @@ func NewResult(requestID uuid.UUID, pageNumber int, originalPath, translatedPath
 	}
 }
 
+// NewMissingResult creates a result for a page the worker skipped.
+// The page keeps its original so the reader can still show it.
+func NewMissingResult(requestID uuid.UUID, pageNumber int, originalPath string) *Result {
+	result := NewResult(requestID, pageNumber, originalPath, "")
+	result.Missing = true
+	return result
+}
+
+// FileURL returns the API path serving relPath from storage/<kind>/<request id>,
+// where kind is "originals" or "translated"
+func FileURL(requestID uuid.UUID, kind, relPath string) string {
+	return fmt.Sprintf("/api/files/%s/%s/%s", requestID, kind, relPath)
+}
+
+// FileRelPath reverses FileURL, returning false if url is not a kind file of the request
+func FileRelPath(requestID uuid.UUID, kind, url string) (string, bool) {
+	relPath, ok := strings.CutPrefix(url, FileURL(requestID, kind, ""))
+	return relPath, ok && relPath != ""
+}
+
 // ResultList represents a collection of results for a request
 type ResultList struct {
 	RequestID uuid.UUID `json:"requestId"`
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// statuses lists every request status in lifecycle order
+var statuses = []RequestStatus{
+	StatusQueued, StatusProcessing, StatusRetrying, StatusCompleted, StatusPartial, StatusFailed, StatusCancelled,
+}
+
+// transitions lists the statuses a request may move to from each status.
+// The repository enforces the same table in SQL, see SourcesOf.
+var transitions = map[RequestStatus][]RequestStatus{
+	StatusQueued: {StatusProcessing, StatusFailed, StatusCancelled},
+	// processing → processing records progress, processing → queued re-enqueues a lost task
+	StatusProcessing: {StatusProcessing, StatusQueued, StatusRetrying, StatusCompleted, StatusPartial, StatusFailed, StatusCancelled},
+	// retrying → processing is asynq running the next attempt
+	StatusRetrying: {StatusProcessing, StatusQueued, StatusFailed, StatusCancelled},
+	// Finished requests only go back to the queue when retried
+	StatusFailed:    {StatusQueued},
+	StatusCompleted: {StatusQueued},
+	StatusCancelled: {StatusQueued},
+	// partial → completed once its missing pages were re-translated
+	StatusPartial: {StatusQueued, StatusCompleted},
+}
+
+// CanTransitionTo reports whether a request in status s may move to status to
+func (s RequestStatus) CanTransitionTo(to RequestStatus) bool {
+	return slices.Contains(transitions[s], to)
+}
+
+// IsTerminal reports whether the status ends a run of the request
+func (s RequestStatus) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusPartial || s == StatusFailed || s == StatusCancelled
+}
+
+// HasResults reports whether a request in status s finished with translated pages to show
+func (s RequestStatus) HasResults() bool {
+	return s == StatusCompleted || s == StatusPartial
+}
+
+// SourcesOf returns the statuses from which a request may move to status to
+func SourcesOf(to RequestStatus) []RequestStatus {
+	var sources []RequestStatus
+	for _, from := range statuses {
+		if from.CanTransitionTo(to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// TransitionError is returned when a request cannot move from its status to another.
+// It matches ErrInvalidState with errors.Is.
+type TransitionError struct {
+	From RequestStatus
+	To   RequestStatus
+}
+
+// Error implements the error interface
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("request is %s, cannot move to %s", e.From, e.To)
+}
+
+// Unwrap returns ErrInvalidState
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidState
+}
+
+// ValidateTransition returns a *TransitionError unless a request may move from one status to the other
+func ValidateTransition(from, to RequestStatus) error {
+	if !from.CanTransitionTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
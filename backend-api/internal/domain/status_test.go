@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCanTransitionTo(t *testing.T) {
+	allowed := map[RequestStatus][]RequestStatus{
+		StatusQueued:     {StatusProcessing, StatusFailed, StatusCancelled},
+		StatusProcessing: {StatusProcessing, StatusQueued, StatusRetrying, StatusCompleted, StatusPartial, StatusFailed, StatusCancelled},
+		StatusRetrying:   {StatusProcessing, StatusQueued, StatusFailed, StatusCancelled},
+		StatusCompleted:  {StatusQueued},
+		StatusPartial:    {StatusQueued, StatusCompleted},
+		StatusFailed:     {StatusQueued},
+		StatusCancelled:  {StatusQueued},
+	}
+
+	// Every pair of statuses, so a new transition cannot slip in unnoticed
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := slices.Contains(allowed[from], to)
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: got %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestCanTransitionToUnknownStatus(t *testing.T) {
+	if RequestStatus("paused").CanTransitionTo(StatusQueued) {
+		t.Error("unknown status must not transition")
+	}
+	if StatusQueued.CanTransitionTo(RequestStatus("paused")) {
+		t.Error("no status may transition to an unknown status")
+	}
+}
+
+func TestSourcesOf(t *testing.T) {
+	tests := []struct {
+		to   RequestStatus
+		want []RequestStatus
+	}{
+		{StatusQueued, []RequestStatus{StatusProcessing, StatusRetrying, StatusCompleted, StatusPartial, StatusFailed, StatusCancelled}},
+		{StatusProcessing, []RequestStatus{StatusQueued, StatusProcessing, StatusRetrying}},
+		{StatusRetrying, []RequestStatus{StatusProcessing}},
+		{StatusCompleted, []RequestStatus{StatusProcessing, StatusPartial}},
+		{StatusPartial, []RequestStatus{StatusProcessing}},
+		{StatusFailed, []RequestStatus{StatusQueued, StatusProcessing, StatusRetrying}},
+		{StatusCancelled, []RequestStatus{StatusQueued, StatusProcessing, StatusRetrying}},
+	}
+
+	for _, tt := range tests {
+		if got := SourcesOf(tt.to); !slices.Equal(got, tt.want) {
+			t.Errorf("SourcesOf(%s) = %v, want %v", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTransition(t *testing.T) {
+	if err := ValidateTransition(StatusQueued, StatusProcessing); err != nil {
+		t.Fatalf("queued -> processing: unexpected error %v", err)
+	}
+
+	err := ValidateTransition(StatusCompleted, StatusProcessing)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("completed -> processing: got %v, want *TransitionError", err)
+	}
+	if transitionErr.From != StatusCompleted || transitionErr.To != StatusProcessing {
+		t.Errorf("got %s -> %s, want completed -> processing", transitionErr.From, transitionErr.To)
+	}
+	if !errors.Is(err, ErrInvalidState) {
+		t.Error("TransitionError must match ErrInvalidState")
+	}
+}
+
+func TestRequestUpdateStatus(t *testing.T) {
+	request := NewRequest("page.jpg", FileTypeImage)
+
+	if err := request.UpdateStatus(StatusProcessing, 40); err != nil {
+		t.Fatalf("queued -> processing: %v", err)
+	}
+	if request.Progress != 40 || request.CompletedAt != nil {
+		t.Errorf("processing: progress %d, completedAt %v", request.Progress, request.CompletedAt)
+	}
+
+	if err := request.UpdateStatus(StatusCompleted, 100); err != nil {
+		t.Fatalf("processing -> completed: %v", err)
+	}
+	if request.CompletedAt == nil {
+		t.Error("completed request must have completedAt")
+	}
+
+	// A late progress update must not revive a completed request
+	if err := request.UpdateStatus(StatusProcessing, 50); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("completed -> processing: got %v, want ErrInvalidState", err)
+	}
+	if request.Status != StatusCompleted || request.Progress != 100 {
+		t.Errorf("rejected transition changed the request: %s %d", request.Status, request.Progress)
+	}
+}
+
+func TestRetryingAttempt(t *testing.T) {
+	request := NewRequest("page.jpg", FileTypeImage)
+
+	// A failed attempt waits for the next one, which runs as processing again
+	for _, status := range []RequestStatus{StatusProcessing, StatusRetrying, StatusProcessing, StatusCompleted} {
+		if err := request.UpdateStatus(status, 0); err != nil {
+			t.Fatalf("-> %s: %v", status, err)
+		}
+	}
+
+	retrying := NewRequest("page.jpg", FileTypeImage)
+	retrying.Status = StatusRetrying
+	if retrying.IsCompleted() || !retrying.CanCancel() {
+		t.Error("a retrying request is still running and can be cancelled")
+	}
+	if err := retrying.UpdateStatus(StatusCompleted, 100); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("retrying -> completed: got %v, want ErrInvalidState", err)
+	}
+}
+
+func TestRequestSetError(t *testing.T) {
+	request := NewRequest("page.jpg", FileTypeImage)
+	if err := request.SetError("boom"); err != nil {
+		t.Fatalf("queued -> failed: %v", err)
+	}
+	if request.Status != StatusFailed || request.ErrorMessage == nil || *request.ErrorMessage != "boom" || request.CompletedAt == nil {
+		t.Errorf("unexpected failed request: %+v", request)
+	}
+
+	cancelled := NewRequest("page.jpg", FileTypeImage)
+	cancelled.Status = StatusCancelled
+	if err := cancelled.SetError("boom"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancelled -> failed: got %v, want ErrInvalidState", err)
+	}
+	if cancelled.ErrorMessage != nil {
+		t.Error("rejected transition set the error message")
+	}
+}
+
+func TestRequestReset(t *testing.T) {
+	for _, status := range []RequestStatus{StatusCompleted, StatusPartial, StatusFailed, StatusCancelled} {
+		request := NewRequest("page.jpg", FileTypeImage)
+		request.Status = status
+		request.Progress = 100
+		message := "boom"
+		request.ErrorMessage = &message
+
+		if err := request.Reset(); err != nil {
+			t.Fatalf("%s -> queued: %v", status, err)
+		}
+		if request.Status != StatusQueued || request.Progress != 0 || request.ErrorMessage != nil || request.CompletedAt != nil {
+			t.Errorf("%s: unexpected reset request: %+v", status, request)
+		}
+	}
+
+	request := NewRequest("page.jpg", FileTypeImage)
+	if err := request.Reset(); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("queued -> queued: got %v, want ErrInvalidState", err)
+	}
+}
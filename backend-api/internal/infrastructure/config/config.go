@@ -9,13 +9,18 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Worker   WorkerConfig
-	Storage  StorageConfig
-	CORS     CORSConfig
-	Logging  LoggingConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	Worker    WorkerConfig
+	Storage   StorageConfig
+	Archive   ArchiveConfig
+	Retention RetentionConfig
+	Outbox    OutboxConfig
+	Reconcile ReconcileConfig
+	Admin     AdminConfig
+	CORS      CORSConfig
+	Logging   LoggingConfig
 }
 
 type ServerConfig struct {
@@ -39,17 +44,105 @@ type RedisConfig struct {
 	DB       int
 }
 
+// Worker modes
+const (
+	WorkerModeProcess = "process" // one main.py process per job
+	WorkerModeDaemon  = "daemon"  // warm daemon.py processes fed with jobs
+	WorkerModeRemote  = "remote"  // a worker service reached over HTTP
+	WorkerModeFake    = "fake"    // built-in executor that stamps pages instead of translating them
+)
+
+// WorkerConfig configures the translation worker. A job gets Timeout plus PageTimeout per
+// page before it is stopped, and is stopped sooner when it reports no progress for
+// StallTimeout. A zero Timeout or StallTimeout disables that limit.
+// A stopped Python worker gets KillGrace to exit after SIGTERM before it is killed.
 type WorkerConfig struct {
-	PythonPath  string
-	WorkerPath  string
-	Concurrency int
-	Timeout     time.Duration
+	PythonPath   string
+	WorkerPath   string
+	Concurrency  int
+	Timeout      time.Duration
+	PageTimeout  time.Duration
+	StallTimeout time.Duration
+	KillGrace    time.Duration
+	Mode         string
+	Limits       ResourceLimits
+	Daemon       DaemonConfig
+	Remote       RemoteWorkerConfig
+	Fake         FakeWorkerConfig
+}
+
+// ResourceLimits are the rlimits applied to the Python worker processes and inherited by
+// the processes they spawn. A daemon is bound by them for its whole life, not per job.
+// A zero limit leaves the inherited one in place.
+type ResourceLimits struct {
+	MemoryMB  int64         // private writable memory (RLIMIT_DATA), which ignores the address space CUDA reserves
+	CPUTime   time.Duration // CPU time (RLIMIT_CPU)
+	OpenFiles int           // open file descriptors (RLIMIT_NOFILE)
+}
+
+// FakeWorkerConfig shapes the fake mode, which needs neither Python nor a GPU
+type FakeWorkerConfig struct {
+	PageDelay       time.Duration // time spent on each page
+	FailureRate     float64       // probability, between 0 and 1, that a job fails midway
+	PageFailureRate float64       // probability, between 0 and 1, that a page fails
+}
+
+// RemoteWorkerConfig locates the worker service used in remote mode
+type RemoteWorkerConfig struct {
+	URL   string // base URL of the service, e.g. http://gpu-box:8090
+	Token string // bearer token sent to the service, empty sends none
+}
+
+// DaemonConfig controls the warm worker processes of the daemon mode.
+// A zero limit disables the corresponding recycling.
+type DaemonConfig struct {
+	MaxJobs        int           // jobs after which a daemon is replaced
+	MaxMemoryMB    int64         // resident memory after which a daemon is replaced
+	HealthInterval time.Duration // how often a daemon is pinged, it is restarted after missing 3 pings
+	CancelGrace    time.Duration // how long a cancelled job may take to stop before its daemon is killed
 }
 
 type StorageConfig struct {
-	Path          string
-	MaxUploadSize int64
-	DockerPath    string // Docker container's storage path, used by host worker to rewrite paths
+	Path                   string
+	MaxUploadSize          int64
+	MaxResumableUploadSize int64         // Limit for resumable (tus) uploads, which are not bound by the request body limit
+	IdempotencyWindow      time.Duration // How long an Idempotency-Key replays the upload it created
+	DockerPath             string        // Docker container's storage path, used by host worker to rewrite paths
+}
+
+// ArchiveConfig bounds what an uploaded archive may expand to
+type ArchiveConfig struct {
+	MaxEntries          int
+	MaxUncompressedSize int64
+	MaxCompressionRatio float64
+}
+
+// RetentionConfig controls the storage cleanup scheduled by the worker.
+// A zero age disables the corresponding policy.
+type RetentionConfig struct {
+	Schedule  string        // cron spec of the cleanup job, empty disables it
+	UploadAge time.Duration // uploads of completed requests are removed after this age
+	FailedAge time.Duration // failed and cancelled requests are purged after this age
+	OrphanAge time.Duration // directories without a request, and stale temp directories, are swept after this age
+	DryRun    bool          // report what would be removed without deleting anything
+}
+
+// OutboxConfig controls how the API pushes outbox tasks to the queue
+type OutboxConfig struct {
+	PollInterval time.Duration // how often due tasks are looked up
+	BatchSize    int           // tasks claimed per query
+	MaxBackoff   time.Duration // upper bound of the delay between attempts of a failing task
+}
+
+// ReconcileConfig controls the reconciler scheduled by the worker
+type ReconcileConfig struct {
+	Schedule   string        // cron spec of the reconcile job, empty disables it
+	StaleAfter time.Duration // queued and processing requests not updated for this long are checked
+}
+
+// AdminConfig protects the /api/admin endpoints
+type AdminConfig struct {
+	Token string // bearer token required by admin endpoints, empty leaves them open
 }
 
 type CORSConfig struct {
@@ -97,15 +190,65 @@ func Load() (*Config, error) {
 			DB:       viper.GetInt("REDIS_DB"),
 		},
 		Worker: WorkerConfig{
-			PythonPath:  getEnvOrDefault("PYTHON_PATH", "python"),
-			WorkerPath:  getEnvOrDefault("WORKER_PATH", "../ai-worker"),
-			Concurrency: getIntOrDefault("WORKER_CONCURRENCY", 1),
-			Timeout:     time.Duration(getIntOrDefault("WORKER_TIMEOUT", 600)) * time.Second,
+			PythonPath:   getEnvOrDefault("PYTHON_PATH", "python"),
+			WorkerPath:   getEnvOrDefault("WORKER_PATH", "../ai-worker"),
+			Concurrency:  getIntOrDefault("WORKER_CONCURRENCY", 1),
+			Timeout:      time.Duration(getIntOrDefault("WORKER_TIMEOUT", 600)) * time.Second,
+			PageTimeout:  time.Duration(getIntOrDefault("WORKER_PAGE_TIMEOUT", 60)) * time.Second,
+			StallTimeout: time.Duration(getIntOrDefault("WORKER_STALL_TIMEOUT", 300)) * time.Second,
+			KillGrace:    time.Duration(getIntOrDefault("WORKER_KILL_GRACE_SECONDS", 10)) * time.Second,
+			Mode:         getEnvOrDefault("WORKER_MODE", WorkerModeProcess),
+			Limits: ResourceLimits{
+				MemoryMB:  int64(getIntOrDefault("WORKER_LIMIT_MEMORY_MB", 0)),
+				CPUTime:   time.Duration(getIntOrDefault("WORKER_LIMIT_CPU_SECONDS", 0)) * time.Second,
+				OpenFiles: getIntOrDefault("WORKER_LIMIT_OPEN_FILES", 0),
+			},
+			Daemon: DaemonConfig{
+				MaxJobs:        getIntOrDefault("WORKER_DAEMON_MAX_JOBS", 50),
+				MaxMemoryMB:    int64(getIntOrDefault("WORKER_DAEMON_MAX_MEMORY_MB", 0)),
+				HealthInterval: time.Duration(getIntOrDefault("WORKER_DAEMON_HEALTH_SECONDS", 30)) * time.Second,
+				CancelGrace:    time.Duration(getIntOrDefault("WORKER_DAEMON_CANCEL_GRACE_SECONDS", 60)) * time.Second,
+			},
+			Remote: RemoteWorkerConfig{
+				URL:   getEnvOrDefault("WORKER_REMOTE_URL", ""),
+				Token: getEnvOrDefault("WORKER_REMOTE_TOKEN", ""),
+			},
+			Fake: FakeWorkerConfig{
+				PageDelay:       time.Duration(getIntOrDefault("WORKER_FAKE_PAGE_DELAY_MS", 500)) * time.Millisecond,
+				FailureRate:     getFloatOrDefault("WORKER_FAKE_FAILURE_RATE", 0),
+				PageFailureRate: getFloatOrDefault("WORKER_FAKE_PAGE_FAILURE_RATE", 0),
+			},
 		},
 		Storage: StorageConfig{
-			Path:          getEnvOrDefault("STORAGE_PATH", "./storage"),
-			MaxUploadSize: int64(getIntOrDefault("MAX_UPLOAD_SIZE", 104857600)), // 100MB
-			DockerPath:    getEnvOrDefault("STORAGE_PATH_DOCKER", ""),
+			Path:                   getEnvOrDefault("STORAGE_PATH", "./storage"),
+			MaxUploadSize:          int64(getIntOrDefault("MAX_UPLOAD_SIZE", 104857600)),            // 100MB
+			MaxResumableUploadSize: int64(getIntOrDefault("MAX_RESUMABLE_UPLOAD_SIZE", 2147483648)), // 2GB
+			DockerPath:             getEnvOrDefault("STORAGE_PATH_DOCKER", ""),
+			IdempotencyWindow:      time.Duration(getIntOrDefault("IDEMPOTENCY_WINDOW_HOURS", 24)) * time.Hour,
+		},
+		Archive: ArchiveConfig{
+			MaxEntries:          getIntOrDefault("ARCHIVE_MAX_ENTRIES", 5000),
+			MaxUncompressedSize: int64(getIntOrDefault("ARCHIVE_MAX_UNCOMPRESSED_SIZE", 4294967296)), // 4GB
+			MaxCompressionRatio: getFloatOrDefault("ARCHIVE_MAX_COMPRESSION_RATIO", 100),
+		},
+		Retention: RetentionConfig{
+			Schedule:  getEnvOrDefault("RETENTION_SCHEDULE", "@every 1h"),
+			UploadAge: time.Duration(getIntOrDefault("RETENTION_UPLOAD_DAYS", 30)) * 24 * time.Hour,
+			FailedAge: time.Duration(getIntOrDefault("RETENTION_FAILED_DAYS", 7)) * 24 * time.Hour,
+			OrphanAge: time.Duration(getIntOrDefault("RETENTION_ORPHAN_HOURS", 24)) * time.Hour,
+			DryRun:    getBoolOrDefault("RETENTION_DRY_RUN", true),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: time.Duration(getIntOrDefault("OUTBOX_POLL_SECONDS", 5)) * time.Second,
+			BatchSize:    getIntOrDefault("OUTBOX_BATCH_SIZE", 50),
+			MaxBackoff:   time.Duration(getIntOrDefault("OUTBOX_MAX_BACKOFF_SECONDS", 300)) * time.Second,
+		},
+		Reconcile: ReconcileConfig{
+			Schedule:   getEnvOrDefault("RECONCILE_SCHEDULE", "@every 5m"),
+			StaleAfter: time.Duration(getIntOrDefault("RECONCILE_STALE_MINUTES", 30)) * time.Minute,
+		},
+		Admin: AdminConfig{
+			Token: getEnvOrDefault("ADMIN_TOKEN", ""),
 		},
 		CORS: CORSConfig{
 			Origins: viper.GetStringSlice("CORS_ORIGINS"),
@@ -145,6 +288,37 @@ func (c *Config) Validate() error {
 	if c.Worker.WorkerPath == "" {
 		return fmt.Errorf("worker path is required")
 	}
+	switch c.Worker.Mode {
+	case WorkerModeProcess, WorkerModeDaemon, WorkerModeRemote, WorkerModeFake:
+	default:
+		return fmt.Errorf("worker mode must be %q, %q, %q or %q", WorkerModeProcess, WorkerModeDaemon, WorkerModeRemote, WorkerModeFake)
+	}
+	if c.Worker.Mode == WorkerModeRemote && c.Worker.Remote.URL == "" {
+		return fmt.Errorf("worker remote URL is required in remote mode")
+	}
+	if c.Worker.Mode == WorkerModeDaemon && c.Worker.Daemon.HealthInterval <= 0 {
+		return fmt.Errorf("worker daemon health interval must be positive")
+	}
+	if c.Worker.Timeout < 0 || c.Worker.PageTimeout < 0 || c.Worker.StallTimeout < 0 {
+		return fmt.Errorf("worker timeouts cannot be negative")
+	}
+	if c.Worker.KillGrace < 0 {
+		return fmt.Errorf("worker kill grace cannot be negative")
+	}
+	if c.Worker.Limits.MemoryMB < 0 || c.Worker.Limits.CPUTime < 0 || c.Worker.Limits.OpenFiles < 0 {
+		return fmt.Errorf("worker resource limits cannot be negative")
+	}
+	if c.Worker.Fake.PageDelay < 0 {
+		return fmt.Errorf("worker fake page delay cannot be negative")
+	}
+	for _, rate := range []float64{c.Worker.Fake.FailureRate, c.Worker.Fake.PageFailureRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("worker fake failure rates must be between 0 and 1")
+		}
+	}
+	if c.Outbox.PollInterval <= 0 || c.Outbox.BatchSize <= 0 {
+		return fmt.Errorf("outbox poll interval and batch size must be positive")
+	}
 	return nil
 }
 
@@ -167,3 +341,15 @@ func getIntOrDefault(key string, defaultValue int) int {
 	viper.SetDefault(key, defaultValue)
 	return viper.GetInt(key)
 }
+
+// getFloatOrDefault gets float environment variable or returns default value
+func getFloatOrDefault(key string, defaultValue float64) float64 {
+	viper.SetDefault(key, defaultValue)
+	return viper.GetFloat64(key)
+}
+
+// getBoolOrDefault gets boolean environment variable or returns default value
+func getBoolOrDefault(key string, defaultValue bool) bool {
+	viper.SetDefault(key, defaultValue)
+	return viper.GetBool(key)
+}
@@ -6,19 +6,12 @@ import (
 	"fmt"
 
 	"github.com/P4ST4S/manga-translator/backend-api/internal/infrastructure/config"
+	"github.com/P4ST4S/manga-translator/backend-api/internal/ports"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-// ProgressUpdate represents a progress update message
-type ProgressUpdate struct {
-	RequestID uuid.UUID `json:"requestId"`
-	Status    string    `json:"status"`
-	Progress  int       `json:"progress"`
-	Message   string    `json:"message"`
-}
-
 // Publisher handles publishing messages to Redis
 type Publisher struct {
 	client *redis.Client
@@ -48,7 +41,7 @@ func NewPublisher(cfg *config.RedisConfig, logger *zap.Logger) (*Publisher, erro
 }
 
 // PublishProgress publishes a progress update to a request-specific channel
-func (p *Publisher) PublishProgress(ctx context.Context, update ProgressUpdate) error {
+func (p *Publisher) PublishProgress(ctx context.Context, update ports.ProgressUpdate) error {
 	channel := fmt.Sprintf("request:%s:progress", update.RequestID)
 
 	data, err := json.Marshal(update)
@@ -103,7 +96,7 @@ func NewSubscriber(cfg *config.RedisConfig, logger *zap.Logger) (*Subscriber, er
 }
 
 // SubscribeToProgress subscribes to progress updates for a specific request
-func (s *Subscriber) SubscribeToProgress(ctx context.Context, requestID uuid.UUID) (<-chan ProgressUpdate, error) {
+func (s *Subscriber) SubscribeToProgress(ctx context.Context, requestID uuid.UUID) (<-chan ports.ProgressUpdate, error) {
 	channel := fmt.Sprintf("request:%s:progress", requestID)
 
 	pubsub := s.client.Subscribe(ctx, channel)
@@ -119,7 +112,7 @@ func (s *Subscriber) SubscribeToProgress(ctx context.Context, requestID uuid.UUI
 	)
 
 	// Create channel for progress updates
-	updates := make(chan ProgressUpdate, 10)
+	updates := make(chan ports.ProgressUpdate, 10)
 
 	// Start goroutine to receive messages
 	go func() {
@@ -139,7 +132,7 @@ func (s *Subscriber) SubscribeToProgress(ctx context.Context, requestID uuid.UUI
 					return
 				}
 
-				var update ProgressUpdate
+				var update ports.ProgressUpdate
 				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
 					s.logger.Error("failed to unmarshal progress update",
 						zap.Error(err),
@@ -0,0 +1,57 @@
+// Package natsort orders page paths the way a reader expects them
+package natsort
+
+import (
+	"sort"
+	"strings"
+)
+
+// Less compares two paths the way a reader expects pages to be ordered:
+// runs of digits compare by numeric value, so "2.jpg" sorts before "10.jpg".
+func Less(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		aDigits, bDigits := isDigit(a[0]), isDigit(b[0])
+		switch {
+		case aDigits && bDigits:
+			aNum, aRest := splitDigits(a)
+			bNum, bRest := splitDigits(b)
+			aTrim, bTrim := strings.TrimLeft(aNum, "0"), strings.TrimLeft(bNum, "0")
+			if len(aTrim) != len(bTrim) {
+				return len(aTrim) < len(bTrim)
+			}
+			if aTrim != bTrim {
+				return aTrim < bTrim
+			}
+			// Same value: fewer leading zeros first, for a stable total order
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			a, b = aRest, bRest
+		case a[0] != b[0]:
+			return a[0] < b[0]
+		default:
+			a, b = a[1:], b[1:]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// Sort sorts paths in natural order
+func Sort(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return Less(paths[i], paths[j])
+	})
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+)
+
+// PageSet is an upload normalised into an ordered set of pages
+type PageSet struct {
+	// ArchivePath is a ZIP holding the pages in reading order, ready for the worker
+	ArchivePath string
+
+	// Pages lists the entry names of ArchivePath in reading order
+	Pages []string
+}
+
+// Ingester converts uploaded archives and documents into a page set the worker can process
+type Ingester interface {
+	// Ingest extracts the pages of srcPath and writes them to a ZIP inside destDir
+	Ingest(ctx context.Context, srcPath string, fileType domain.FileType, destDir string) (*PageSet, error)
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProgressUpdate represents a progress update message
+type ProgressUpdate struct {
+	RequestID uuid.UUID `json:"requestId"`
+	Status    string    `json:"status"`
+	Progress  int       `json:"progress"`
+	Message   string    `json:"message"`
+	Page      int       `json:"page,omitempty"` // set when only one page is being re-translated
+
+	// Set on retrying updates: the attempt that failed and when the next one runs
+	Attempt       int        `json:"attempt,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+}
+
+// ProgressPublisher sends progress updates to the clients following a request
+type ProgressPublisher interface {
+	// PublishProgress publishes an update on the channel of its request
+	PublishProgress(ctx context.Context, update ProgressUpdate) error
+}
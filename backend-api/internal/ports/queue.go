@@ -3,13 +3,40 @@ package ports
 import (
 	"context"
 
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/google/uuid"
 )
 
 // QueueClient defines the interface for job queue operations
 type QueueClient interface {
-	// EnqueueTranslation enqueues a translation job
-	EnqueueTranslation(ctx context.Context, requestID uuid.UUID, filePath string, fileType string) error
+	// EnqueueTranslation enqueues a translation job over pages pages with the request's
+	// translation options in the queue of its priority; the page count bounds how long the
+	// job may run. Returns domain.ErrInvalidState if the request already has a waiting or running task.
+	EnqueueTranslation(ctx context.Context, requestID uuid.UUID, filePath string, fileType string, pages int, options domain.TranslationOptions, priority domain.Priority) error
+
+	// EnqueuePageTranslation enqueues the re-translation of one page of a completed request.
+	// Returns domain.ErrInvalidState if that page is already queued or being re-translated.
+	EnqueuePageTranslation(ctx context.Context, requestID uuid.UUID, pageNumber int, options domain.TranslationOptions, priority domain.Priority) error
+
+	// HasPageTranslation reports whether one of the first pageCount pages of a request has a
+	// re-translation waiting or running
+	HasPageTranslation(ctx context.Context, requestID uuid.UUID, pageCount int) (bool, error)
+
+	// MoveTranslation moves the waiting task of a request to the queue of another priority,
+	// keeping its schedule. Returns domain.ErrInvalidState if the task is already running,
+	// waiting for a retry or finished.
+	MoveTranslation(ctx context.Context, requestID uuid.UUID, priority domain.Priority) error
+
+	// CancelTranslation deletes the request's task if it is still waiting, or signals the
+	// worker running it to stop. It is a no-op if the task no longer exists.
+	CancelTranslation(ctx context.Context, requestID uuid.UUID) error
+
+	// TranslationTask returns the task of a request, or nil if it is in no queue
+	TranslationTask(ctx context.Context, requestID uuid.UUID) (*domain.TaskInfo, error)
+
+	// DeleteTranslation removes the request's task from the queue whatever its state.
+	// A running task is cancelled instead, asynq drops it once the handler returns.
+	DeleteTranslation(ctx context.Context, requestID uuid.UUID) error
 
 	// Close closes the queue client
 	Close() error
@@ -23,3 +50,10 @@ type QueueServer interface {
 	// Stop stops the queue server
 	Stop() error
 }
+
+// Reconciler repairs queued and processing requests whose task was lost or gave up
+type Reconciler interface {
+	// Reconcile compares stale requests with their tasks, re-enqueueing lost work and
+	// failing hopeless requests. In dry-run mode it only reports what it would do.
+	Reconcile(ctx context.Context, dryRun bool) (*domain.ReconcileReport, error)
+}
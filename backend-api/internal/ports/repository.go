@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 	"github.com/google/uuid"
@@ -12,17 +13,59 @@ type RequestRepository interface {
 	// Create creates a new request
 	Create(ctx context.Context, request *domain.Request) error
 
+	// CreateWithTask creates a new request and the outbox task enqueueing it in one transaction
+	CreateWithTask(ctx context.Context, request *domain.Request, task *domain.OutboxTask) error
+
 	// GetByID retrieves a request by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Request, error)
 
 	// List retrieves requests with optional filtering
 	List(ctx context.Context, filter RequestFilter) ([]*domain.Request, int, error)
 
-	// Update updates an existing request
+	// FindByIdempotencyKey retrieves the requests created since the given time by uploads
+	// sent with the Idempotency-Key key, oldest first
+	FindByIdempotencyKey(ctx context.Context, key string, since time.Time) ([]*domain.Request, error)
+
+	// ReleaseIdempotencyKey detaches the Idempotency-Key key from the requests created before
+	// the given time, so a new upload may use it again
+	ReleaseIdempotencyKey(ctx context.Context, key string, before time.Time) error
+
+	// RequeueWithTask saves a request reset to queued, drops its results and creates the
+	// outbox task enqueueing it again, in one transaction. Returns a *domain.TransitionError
+	// unless the stored status is one of from.
+	RequeueWithTask(ctx context.Context, request *domain.Request, from []domain.RequestStatus, task *domain.OutboxTask) error
+
+	// Update updates an existing request. The stored status must be the request's status
+	// or one that may move to it, otherwise a *domain.TransitionError is returned.
 	Update(ctx context.Context, request *domain.Request) error
 
-	// UpdateStatus updates request status and progress
+	// UpdateStatus updates request status and progress if the stored status may move to status.
+	// Returns a *domain.TransitionError otherwise, e.g. when the request was cancelled meanwhile.
 	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.RequestStatus, progress int) error
+
+	// TransitionStatus moves a request to status only if its current status is one of from.
+	// Returns a *domain.TransitionError if the request is in another status or if one of
+	// from may never move to status.
+	TransitionStatus(ctx context.Context, id uuid.UUID, from []domain.RequestStatus, to domain.RequestStatus) error
+
+	// Fail marks a request failed with message if its status allows it.
+	// Returns a *domain.TransitionError otherwise.
+	Fail(ctx context.Context, id uuid.UUID, message string) error
+
+	// StartAttempt moves a request to processing for attempt out of maxAttempts and resets
+	// its progress. Returns a *domain.TransitionError if the request can no longer run.
+	StartAttempt(ctx context.Context, id uuid.UUID, attempt, maxAttempts int) error
+
+	// ScheduleRetry moves a processing request to retrying after a failed attempt, recording
+	// the error and when the next attempt runs. Returns a *domain.TransitionError otherwise.
+	ScheduleRetry(ctx context.Context, id uuid.UUID, message string, nextAttemptAt time.Time) error
+
+	// SetPriority changes the priority of a queued request.
+	// Returns domain.ErrInvalidState if the request is no longer queued.
+	SetPriority(ctx context.Context, id uuid.UUID, priority domain.Priority) error
+
+	// Delete deletes a request and, through the foreign key, its results
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 // ResultRepository defines the interface for result data persistence
@@ -36,13 +79,48 @@ type ResultRepository interface {
 	// GetByRequestID retrieves all results for a request
 	GetByRequestID(ctx context.Context, requestID uuid.UUID) ([]*domain.Result, error)
 
+	// GetByPage retrieves the result of one page of a request
+	GetByPage(ctx context.Context, requestID uuid.UUID, pageNumber int) (*domain.Result, error)
+
+	// Update updates the paths of an existing result
+	Update(ctx context.Context, result *domain.Result) error
+
 	// DeleteByRequestID deletes all results for a request
 	DeleteByRequestID(ctx context.Context, requestID uuid.UUID) error
 }
 
+// PageRepository defines the interface for the per-page status of requests
+type PageRepository interface {
+	// Replace sets the pages of a request, dropping those of a previous attempt
+	Replace(ctx context.Context, requestID uuid.UUID, pages []*domain.Page) error
+
+	// GetByRequestID retrieves all pages of a request in page order
+	GetByRequestID(ctx context.Context, requestID uuid.UUID) ([]*domain.Page, error)
+
+	// UpdateStatus sets the status of one page; a nil errorMessage clears it.
+	// Returns domain.ErrNotFound if the request has no such page.
+	UpdateStatus(ctx context.Context, requestID uuid.UUID, pageNumber int, status domain.PageStatus, errorMessage *string) error
+}
+
+// OutboxRepository defines the interface for the outbox of translation tasks
+type OutboxRepository interface {
+	// Claim returns up to limit due tasks and counts an attempt for each. Claimed tasks
+	// are hidden from other dispatchers for lease, after which they are due again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxTask, error)
+
+	// Reschedule makes a task due again after delay, recording why the attempt failed
+	Reschedule(ctx context.Context, id int64, delay time.Duration, lastError string) error
+
+	// Delete removes a task once it was enqueued
+	Delete(ctx context.Context, id int64) error
+}
+
 // RequestFilter represents filtering options for listing requests
 type RequestFilter struct {
-	Status *domain.RequestStatus
-	Limit  int
-	Offset int
+	Status          *domain.RequestStatus
+	Priority        *domain.Priority
+	CompletedBefore *time.Time // only requests that finished before this time
+	UpdatedBefore   *time.Time // only requests last updated before this time
+	Limit           int
+	Offset          int
 }
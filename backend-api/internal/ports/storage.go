@@ -3,6 +3,8 @@ package ports
 import (
 	"context"
 	"io"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
 )
 
 // Storage defines the interface for file storage operations
@@ -19,3 +21,9 @@ type Storage interface {
 	// Exists checks if a file exists
 	Exists(ctx context.Context, path string) (bool, error)
 }
+
+// StorageCleaner applies the storage retention policies
+type StorageCleaner interface {
+	// Cleanup runs every enabled policy once and reports what was reclaimed
+	Cleanup(ctx context.Context) (*domain.CleanupReport, error)
+}
@@ -2,11 +2,24 @@ package ports
 
 import (
 	"context"
+
+	"github.com/P4ST4S/manga-translator/backend-api/internal/domain"
+	"github.com/google/uuid"
 )
 
 // ProgressCallback is called when translation progress is updated
 type ProgressCallback func(progress int, message string)
 
+// PageUpdate reports what the worker did with one page
+type PageUpdate struct {
+	Name   string // slash-separated path of the page inside the input archive, or the image file name
+	Status domain.PageStatus
+	Error  string // why the page was skipped or failed
+}
+
+// PageCallback is called when the worker starts or finishes a page
+type PageCallback func(update PageUpdate)
+
 // TranslationOutput represents the output of a translation job
 type TranslationOutput struct {
 	OutputPath string
@@ -22,6 +35,8 @@ type PageOutput struct {
 
 // WorkerExecutor defines the interface for executing translation jobs
 type WorkerExecutor interface {
-	// Translate executes a translation job
-	Translate(ctx context.Context, inputPath string, onProgress ProgressCallback) (*TranslationOutput, error)
+	// Translate executes the translation job of a request; zero-valued options fall back to the worker defaults.
+	// Scratch files are kept under a directory named after requestID until the job returns.
+	// onPage may be nil.
+	Translate(ctx context.Context, requestID uuid.UUID, inputPath string, options domain.TranslationOptions, onProgress ProgressCallback, onPage PageCallback) (*TranslationOutput, error)
 }
@@ -0,0 +1,48 @@
+// Package testutil holds the fixtures shared by the tests of several packages
+package testutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// WriteZip writes an archive at path holding files, keyed by their slash-separated name
+func WriteZip(t testing.TB, path string, files map[string][]byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// PNGPage returns a blank white page
+func PNGPage(t testing.TB) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,4 @@
+-- Restore original file type constraint
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_file_type_check;
+ALTER TABLE requests ADD CONSTRAINT requests_file_type_check
+    CHECK (file_type IN ('image', 'zip'));
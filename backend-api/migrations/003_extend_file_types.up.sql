@@ -0,0 +1,4 @@
+-- Allow archive and document formats normalised by the ingestion layer
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_file_type_check;
+ALTER TABLE requests ADD CONSTRAINT requests_file_type_check
+    CHECK (file_type IN ('image', 'zip', 'cbz', 'rar', '7z', 'pdf', 'epub'));
@@ -0,0 +1 @@
+ALTER TABLE results DROP COLUMN IF EXISTS missing;
@@ -0,0 +1,2 @@
+-- Pages the worker skipped are stored without a translation
+ALTER TABLE results ADD COLUMN IF NOT EXISTS missing BOOLEAN NOT NULL DEFAULT FALSE;
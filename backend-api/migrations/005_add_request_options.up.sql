@@ -0,0 +1,2 @@
+-- Per-request translation options (languages, font, detection threshold, output quality)
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}'::jsonb;
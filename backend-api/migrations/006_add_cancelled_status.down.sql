@@ -0,0 +1,5 @@
+-- Cancelled requests have no equivalent in the original constraint
+UPDATE requests SET status = 'failed', error_message = 'cancelled' WHERE status = 'cancelled';
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'completed', 'failed'));
@@ -0,0 +1,4 @@
+-- Allow requests to be cancelled while queued or processing
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'completed', 'failed', 'cancelled'));
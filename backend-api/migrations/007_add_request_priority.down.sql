@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_requests_priority;
+ALTER TABLE requests DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,5 @@
+-- Per-request priority, mapped to the asynq queue the task is enqueued in
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal'
+    CHECK (priority IN ('urgent', 'normal', 'bulk'));
+
+CREATE INDEX IF NOT EXISTS idx_requests_priority ON requests(priority);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_requests_idempotency_key;
+ALTER TABLE requests DROP COLUMN IF EXISTS idempotency_hash;
+ALTER TABLE requests DROP COLUMN IF EXISTS idempotency_key;
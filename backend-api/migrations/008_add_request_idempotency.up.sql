@@ -0,0 +1,6 @@
+-- Idempotency-Key of the upload that created a request, with a fingerprint of its body
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS idempotency_hash CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_requests_idempotency_key ON requests(idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Transactional outbox: translation tasks are written in the same transaction as their
+-- request and pushed to asynq by the API's outbox dispatcher
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    request_id UUID NOT NULL REFERENCES requests(id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,
+    file_type VARCHAR(10) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt_at ON outbox(next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_request_id ON outbox(request_id);
@@ -0,0 +1,9 @@
+ALTER TABLE requests DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE requests DROP COLUMN IF EXISTS max_attempts;
+ALTER TABLE requests DROP COLUMN IF EXISTS attempt;
+
+-- Retrying requests have no equivalent in the previous constraint
+UPDATE requests SET status = 'failed' WHERE status = 'retrying';
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'completed', 'failed', 'cancelled'));
@@ -0,0 +1,8 @@
+-- Requests waiting for asynq to retry a failed attempt, and the attempt being run
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'retrying', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
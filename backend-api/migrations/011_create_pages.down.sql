@@ -0,0 +1,7 @@
+-- Partial requests have no equivalent in the previous constraint
+UPDATE requests SET status = 'completed' WHERE status = 'partial';
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'retrying', 'completed', 'failed', 'cancelled'));
+
+DROP TABLE IF EXISTS pages;
@@ -0,0 +1,16 @@
+-- Per-page translation status, updated as the worker reports each page
+CREATE TABLE IF NOT EXISTS pages (
+    request_id UUID NOT NULL REFERENCES requests(id) ON DELETE CASCADE,
+    page_number INTEGER NOT NULL,
+    name VARCHAR(512) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'done', 'skipped', 'failed')),
+    error_message TEXT,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (request_id, page_number)
+);
+
+-- Requests that finished with some pages skipped or failed
+ALTER TABLE requests DROP CONSTRAINT IF EXISTS requests_status_check;
+ALTER TABLE requests ADD CONSTRAINT requests_status_check
+    CHECK (status IN ('queued', 'processing', 'retrying', 'completed', 'partial', 'failed', 'cancelled'));
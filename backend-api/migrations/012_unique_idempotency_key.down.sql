@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_requests_idempotency_key;
+CREATE INDEX IF NOT EXISTS idx_requests_idempotency_key ON requests(idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+ALTER TABLE requests DROP COLUMN IF EXISTS idempotency_seq;
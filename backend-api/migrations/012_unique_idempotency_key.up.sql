@@ -0,0 +1,16 @@
+-- Position of a request among those created by its idempotent upload. The key is unique per
+-- position, so two API instances handling the same upload cannot both create its requests.
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS idempotency_seq SMALLINT;
+
+UPDATE requests r
+SET idempotency_seq = numbered.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY idempotency_key ORDER BY created_at, id) - 1 AS seq
+    FROM requests
+    WHERE idempotency_key IS NOT NULL
+) numbered
+WHERE r.id = numbered.id;
+
+DROP INDEX IF EXISTS idx_requests_idempotency_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_requests_idempotency_key ON requests(idempotency_key, idempotency_seq)
+    WHERE idempotency_key IS NOT NULL;
@@ -16,9 +16,15 @@ export function ResultViewer({ result }: { result: Result }) {
   const [showOriginal, setShowOriginal] = useState(false);
 
   const totalPages = result.pages.length;
-  const currentImage = showOriginal
-    ? `${API_BASE_URL}${result.pages[currentPage].original}`
-    : `${API_BASE_URL}${result.pages[currentPage].translated}`;
+  const page = result.pages[currentPage];
+  const pageStatus = result.pageStatus?.find(
+    (p) => p.pageNumber === page.pageNumber,
+  );
+  // Skipped pages have no translation, show the original instead
+  const currentImage =
+    showOriginal || page.missing
+      ? `${API_BASE_URL}${page.original}`
+      : `${API_BASE_URL}${page.translated}`;
 
   const handlePrev = () => setCurrentPage((p) => Math.max(0, p - 1));
   const handleNext = () =>
@@ -32,6 +38,16 @@ export function ResultViewer({ result }: { result: Result }) {
           <span className="font-display text-lg px-2">
             Page {currentPage + 1} / {totalPages}
           </span>
+          {page.missing && (
+            <span
+              className="text-xs text-muted-foreground"
+              title={pageStatus?.error}
+            >
+              {pageStatus?.status === "skipped"
+                ? "Page skipped"
+                : "Translation missing"}
+            </span>
+          )}
         </div>
 
         <div className="flex items-center gap-4">
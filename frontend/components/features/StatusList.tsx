@@ -9,6 +9,8 @@ import {
   Loader2,
   AlertCircle,
   ArrowRight,
+  XCircle,
+  RotateCw,
 } from "lucide-react";
 import { Button } from "@/components/ui/button";
 import { Card } from "@/components/ui/card";
@@ -43,18 +45,36 @@ const statusConfig: Record<string, StatusConfigItem> = {
     bg: "bg-accent/10 border-accent",
     animate: true,
   },
+  retrying: {
+    icon: RotateCw,
+    color: "text-amber-600",
+    label: "Retrying...",
+    bg: "bg-amber-100 border-amber-600",
+  },
   completed: {
     icon: CheckCircle2,
     color: "text-green-600",
     label: "Completed",
     bg: "bg-green-100 border-green-600",
   },
+  partial: {
+    icon: CheckCircle2,
+    color: "text-amber-600",
+    label: "Partial",
+    bg: "bg-amber-100 border-amber-600",
+  },
   failed: {
     icon: AlertCircle,
     color: "text-red-600",
     label: "Failed",
     bg: "bg-red-100 border-red-600",
   },
+  cancelled: {
+    icon: XCircle,
+    color: "text-muted-foreground",
+    label: "Cancelled",
+    bg: "bg-muted",
+  },
 };
 
 export function StatusList() {
@@ -73,7 +93,10 @@ export function StatusList() {
   // Subscribe to SSE for processing requests
   useEffect(() => {
     const processingRequests = requests.filter(
-      (r) => r.status === "processing" || r.status === "queued",
+      (r) =>
+        r.status === "processing" ||
+        r.status === "queued" ||
+        r.status === "retrying",
     );
     const eventSources: EventSource[] = [];
 
@@ -91,6 +114,8 @@ export function StatusList() {
                     ...r,
                     progress: update.progress,
                     status: update.status as any,
+                    attempt: update.attempt ?? r.attempt,
+                    nextAttemptAt: update.nextAttemptAt,
                   }
                 : r,
             ),
@@ -102,7 +127,11 @@ export function StatusList() {
           setRequests((prev) =>
             prev.map((r) =>
               r.id === req.id
-                ? { ...r, status: "completed" as any, progress: 100 }
+                ? {
+                    ...r,
+                    status: (update.status || "completed") as any,
+                    progress: 100,
+                  }
                 : r,
             ),
           );
@@ -119,6 +148,15 @@ export function StatusList() {
             ),
           );
         },
+        () => {
+          // Cancelled
+          console.log(`[StatusList] Cancelled ${req.id}`);
+          setRequests((prev) =>
+            prev.map((r) =>
+              r.id === req.id ? { ...r, status: "cancelled" as any } : r,
+            ),
+          );
+        },
       );
       eventSources.push(es);
     });
@@ -234,6 +272,14 @@ export function StatusList() {
                     {new Date(req.createdAt).toLocaleDateString()}
                   </p>
 
+                  {req.status === "retrying" && req.nextAttemptAt && (
+                    <p className="text-sm text-amber-600 mb-3">
+                      Attempt {req.attempt} of {req.maxAttempts} failed, next
+                      attempt at{" "}
+                      {new Date(req.nextAttemptAt).toLocaleTimeString()}
+                    </p>
+                  )}
+
                   {/* Progress Bar for processing */}
                   {req.status === "processing" && (
                     <div className="w-full h-3 bg-muted border border-border rounded-full overflow-hidden relative">
@@ -251,7 +297,7 @@ export function StatusList() {
 
                 {/* Actions */}
                 <div className="shrink-0">
-                  {req.status === "completed" && (
+                  {(req.status === "completed" || req.status === "partial") && (
                     <Link href={`/results/${req.id}`}>
                       <Button className="group-hover:translate-x-1 transition-transform">
                         Read <ArrowRight className="ml-2 w-4 h-4" />
@@ -27,6 +27,12 @@ export function UploadZone() {
     accept: {
       "application/zip": [".zip"],
       "application/x-zip-compressed": [".zip"],
+      "application/vnd.comicbook+zip": [".cbz"],
+      "application/vnd.comicbook-rar": [".cbr"],
+      "application/vnd.rar": [".rar"],
+      "application/x-7z-compressed": [".7z", ".cb7"],
+      "application/pdf": [".pdf"],
+      "application/epub+zip": [".epub"],
       "image/png": [".png"],
       "image/jpeg": [".jpg", ".jpeg"],
       "image/webp": [".webp"],
@@ -45,11 +51,11 @@ export function UploadZone() {
     setError(null);
 
     try {
-      const request = await uploadFiles(files);
-      console.log("Upload successful:", request);
+      const response = await uploadFiles(files);
+      console.log("Upload successful:", response);
 
       // Redirect to status page to see progress
-      router.push(`/status?highlight=${request.id}`);
+      router.push(`/status?highlight=${response.requests[0].id}`);
     } catch (err) {
       console.error("Upload failed:", err);
       setError(err instanceof Error ? err.message : "Upload failed");
@@ -10,32 +10,72 @@ const API_BASE_URL =
 export interface Request {
   id: string;
   filename: string;
-  fileType: "image" | "zip";
-  status: "queued" | "processing" | "completed" | "failed";
+  fileType: "image" | "zip" | "cbz" | "rar" | "7z" | "pdf" | "epub";
+  status:
+    | "queued"
+    | "processing"
+    | "retrying"
+    | "completed"
+    | "partial" // completed, but some pages were skipped or failed
+    | "failed"
+    | "cancelled";
+  priority: "urgent" | "normal" | "bulk";
   progress: number;
   pageCount: number;
   thumbnail?: string;
   errorMessage?: string;
+  options: TranslationOptions;
   createdAt: string;
   updatedAt: string;
   completedAt?: string;
+  attempt: number; // attempt being run, out of maxAttempts
+  maxAttempts: number;
+  nextAttemptAt?: string; // set while retrying
 }
 
 export interface Page {
   pageNumber: number;
   original: string;
   translated: string;
+  missing: boolean; // the worker skipped this page, `translated` is empty
+}
+
+// Status of one page as reported by the worker
+export interface PageStatus {
+  pageNumber: number;
+  name: string; // path of the page inside the upload
+  status: "pending" | "processing" | "done" | "skipped" | "failed";
+  error?: string;
+  updatedAt: string;
 }
 
 export interface Result {
   requestId: string;
+  status: Request["status"];
   pages: Page[];
+  pageStatus: PageStatus[];
 }
 
 export interface ProgressUpdate {
   status: string;
   progress: number;
   message: string;
+  attempt?: number; // set on retrying events: the attempt that failed
+  nextAttemptAt?: string;
+}
+
+export interface UploadResponse {
+  requests: Request[];
+  errors: { filename: string; error: string }[];
+}
+
+// Per-request overrides of the worker defaults
+export interface TranslationOptions {
+  sourceLang?: string;
+  targetLang?: string;
+  font?: string;
+  detectionConfidence?: number;
+  outputQuality?: number;
 }
 
 export interface ListRequestsResponse {
@@ -46,15 +86,27 @@ export interface ListRequestsResponse {
 }
 
 // Upload files for translation
-export async function uploadFiles(files: File[]): Promise<Request> {
+export async function uploadFiles(
+  files: File[],
+  options: TranslationOptions = {},
+  // Reuse the same key when retrying so the upload is not translated twice
+  idempotencyKey: string = crypto.randomUUID(),
+): Promise<UploadResponse> {
   const formData = new FormData();
 
   files.forEach((file) => {
     formData.append("files", file);
   });
 
+  Object.entries(options).forEach(([key, value]) => {
+    if (value !== undefined && value !== "") {
+      formData.append(key, String(value));
+    }
+  });
+
   const response = await fetch(`${API_BASE_URL}/api/translate`, {
     method: "POST",
+    headers: { "Idempotency-Key": idempotencyKey },
     body: formData,
   });
 
@@ -101,6 +153,75 @@ export async function getRequest(id: string): Promise<Request> {
   return response.json();
 }
 
+// Cancel a queued or processing request
+export async function cancelRequest(id: string): Promise<Request> {
+  const response = await fetch(`${API_BASE_URL}/api/requests/${id}/cancel`, {
+    method: "POST",
+  });
+
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to cancel request");
+  }
+
+  return response.json();
+}
+
+// Re-run a finished request, optionally with new translation options
+export async function retryRequest(
+  id: string,
+  options?: TranslationOptions,
+): Promise<Request> {
+  const response = await fetch(`${API_BASE_URL}/api/requests/${id}/retry`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(options ? { options } : {}),
+  });
+
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to retry request");
+  }
+
+  return response.json();
+}
+
+// Delete a request and all its files; force also deletes a processing request
+export async function deleteRequest(id: string, force = false): Promise<void> {
+  const response = await fetch(
+    `${API_BASE_URL}/api/requests/${id}${force ? "?force=true" : ""}`,
+    { method: "DELETE" },
+  );
+
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to delete request");
+  }
+}
+
+// Re-translate one page of a completed request, optionally with other options
+export async function retranslatePage(
+  requestId: string,
+  page: number,
+  options?: TranslationOptions,
+): Promise<Page> {
+  const response = await fetch(
+    `${API_BASE_URL}/api/results/${requestId}/pages/${page}/retranslate`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(options ? { options } : {}),
+    },
+  );
+
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to re-translate page");
+  }
+
+  return response.json();
+}
+
 // Get translation results for a request
 export async function getResults(requestId: string): Promise<Result> {
   const response = await fetch(`${API_BASE_URL}/api/results/${requestId}`);
@@ -118,6 +239,7 @@ export function subscribeToProgress(
   onProgress: (update: ProgressUpdate) => void,
   onComplete: (update: ProgressUpdate) => void,
   onError: (error: string) => void,
+  onCancel?: (update: ProgressUpdate) => void,
 ): EventSource {
   const eventSource = new EventSource(
     `${API_BASE_URL}/api/requests/${requestId}/events`,
@@ -145,6 +267,17 @@ export function subscribeToProgress(
     }
   });
 
+  // A failed attempt that will run again; the stream stays open for the next one
+  eventSource.addEventListener("retrying", (e) => {
+    try {
+      const data = JSON.parse(e.data) as ProgressUpdate;
+      console.log("SSE retrying:", data);
+      onProgress(data);
+    } catch (err) {
+      console.error("Failed to parse retrying event:", err, e);
+    }
+  });
+
   eventSource.addEventListener("complete", (e) => {
     try {
       const data = JSON.parse(e.data) as ProgressUpdate;
@@ -157,6 +290,18 @@ export function subscribeToProgress(
     }
   });
 
+  eventSource.addEventListener("cancelled", (e) => {
+    try {
+      const data = JSON.parse(e.data) as ProgressUpdate;
+      console.log("SSE cancelled:", data);
+      hasCompleted = true;
+      onCancel?.(data);
+      eventSource.close();
+    } catch (err) {
+      console.error("Failed to parse cancelled event:", err, e);
+    }
+  });
+
   // Handle custom SSE 'error' event from server (not the native onerror)
   eventSource.addEventListener("error", (e) => {
     const messageEvent = e as MessageEvent;